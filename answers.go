@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// AnswerChecker decides whether an answer solves a quest.
+type AnswerChecker interface {
+	Check(answer string) bool
+}
+
+// AnswerMatch configures how loosely answers are compared with the accepted
+// ones. Answers are always compared case-insensitively with surrounding and
+// repeated spaces ignored.
+type AnswerMatch struct {
+	Normalize []string `json:"normalize,omitempty"` // extra normalisers, see answerNormalizers
+	Tolerance float64  `json:"tolerance,omitempty"` // numbers within this distance match
+	Regex     string   `json:"regex,omitempty"`     // any answer matching this expression is accepted
+	Unordered bool     `json:"unordered,omitempty"` // compare the answer's parts as a set
+}
+
+// answerNormalizers are the normalisers a quest can enable by name.
+var answerNormalizers = map[string]func(string) string{
+	"space":    removeSpaces,
+	"arrows":   unifyArrows,
+	"punct":    stripPunctuation,
+	"translit": transliterate,
+}
+
+// arrowReplacer turns every way of writing an arrow into "→".
+var arrowReplacer = strings.NewReplacer("-->", "→", "—>", "→", "->", "→", "=>", "→", "➡️", "→", "➡", "→", "⟶", "→")
+
+func unifyArrows(s string) string {
+	return arrowReplacer.Replace(s)
+}
+
+func removeSpaces(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// stripPunctuation replaces punctuation with spaces, keeping arrows and the
+// characters numbers are written with.
+func stripPunctuation(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '→' || r == '.' || r == '-' {
+			return r
+		}
+		if unicode.IsPunct(r) {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// cyrillicToLatin transliterates lower-case Russian letters.
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// transliterate writes Cyrillic text in Latin letters so "Орион" and
+// "Orion" compare equal. It expects lower-case input.
+func transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if latin, ok := cyrillicToLatin[r]; ok {
+			b.WriteString(latin)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// answerMatcher is the AnswerChecker built from an AnswerMatch.
+type answerMatcher struct {
+	accepted  []string // accepted answers, normalised
+	normalize []func(string) string
+	regex     *regexp.Regexp
+	tolerance float64
+	unordered bool
+}
+
+// newAnswerChecker builds a checker accepting solution and every answer in
+// answers under the rules of match.
+func newAnswerChecker(solution string, answers []string, match AnswerMatch) (AnswerChecker, error) {
+	m := &answerMatcher{tolerance: match.Tolerance, unordered: match.Unordered}
+
+	for _, name := range match.Normalize {
+		normalize, ok := answerNormalizers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown answer normaliser %q", name)
+		}
+		m.normalize = append(m.normalize, normalize)
+	}
+
+	if match.Regex != "" {
+		re, err := regexp.Compile(`(?i)^(?:` + match.Regex + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid answer regex: %v", err)
+		}
+		m.regex = re
+	}
+
+	if match.Tolerance < 0 {
+		return nil, fmt.Errorf("answer tolerance must not be negative")
+	}
+
+	for _, answer := range append([]string{solution}, answers...) {
+		m.accepted = append(m.accepted, m.clean(answer))
+	}
+	return m, nil
+}
+
+// clean applies the default and the configured normalisers.
+func (m *answerMatcher) clean(s string) string {
+	s = strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	for _, normalize := range m.normalize {
+		s = normalize(s)
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Check implements AnswerChecker.
+func (m *answerMatcher) Check(answer string) bool {
+	if m.regex != nil && m.regex.MatchString(strings.TrimSpace(answer)) {
+		return true
+	}
+
+	got := m.clean(answer)
+	for _, want := range m.accepted {
+		if m.equal(got, want) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *answerMatcher) equal(got, want string) bool {
+	if got == want {
+		return true
+	}
+
+	if m.tolerance > 0 {
+		a, errA := strconv.ParseFloat(got, 64)
+		b, errB := strconv.ParseFloat(want, 64)
+		if errA == nil && errB == nil && math.Abs(a-b) <= m.tolerance {
+			return true
+		}
+	}
+
+	if m.unordered {
+		return sameParts(got, want)
+	}
+	return false
+}
+
+// sameParts reports whether two answers consist of the same parts, split
+// on commas and spaces, in any order.
+func sameParts(a, b string) bool {
+	split := func(s string) []string {
+		parts := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' || unicode.IsSpace(r) })
+		sort.Strings(parts)
+		return parts
+	}
+	pa, pb := split(a), split(b)
+	if len(pa) != len(pb) {
+		return false
+	}
+	for i := range pa {
+		if pa[i] != pb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// checkAnswer reports whether answer solves the quest.
+func (q *Quest) checkAnswer(answer string) bool {
+	if q.Checker == nil {
+		return strings.EqualFold(strings.TrimSpace(answer), q.Solution)
+	}
+	return q.Checker.Check(answer)
+}
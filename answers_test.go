@@ -0,0 +1,118 @@
+package main
+
+import "testing"
+
+func TestAnswerNormalizers(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"space", "a b\tc", "abc"},
+		{"arrows", "A -> B => C --> D ➡ E", "A → B → C → D → E"},
+		{"punct", "орион, вега!", "орион  вега "},
+		{"punct", "-3.5 → x", "-3.5 → x"},
+		{"translit", "орион щука", "orion shchuka"},
+		{"translit", "ъь", ""},
+	}
+	for _, tt := range tests {
+		if got := answerNormalizers[tt.name](tt.in); got != tt.want {
+			t.Errorf("%s(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAnswerChecker(t *testing.T) {
+	tests := []struct {
+		name     string
+		solution string
+		answers  []string
+		match    AnswerMatch
+		accept   []string
+		reject   []string
+	}{
+		{
+			name:     "case and spaces",
+			solution: "Квантовый Ключ",
+			accept:   []string{"квантовый ключ", "  КВАНТОВЫЙ   ключ "},
+			reject:   []string{"квантовыйключ", "ключ"},
+		},
+		{
+			name:     "accepted answers",
+			solution: "42",
+			answers:  []string{"сорок два"},
+			accept:   []string{"42", "Сорок два"},
+			reject:   []string{"43"},
+		},
+		{
+			name:     "arrows and spaces",
+			solution: "A→B→C",
+			match:    AnswerMatch{Normalize: []string{"arrows", "space"}},
+			accept:   []string{"a -> b -> c", "A=>B→C"},
+			reject:   []string{"A→C→B"},
+		},
+		{
+			name:     "transliteration",
+			solution: "Орион",
+			match:    AnswerMatch{Normalize: []string{"translit"}},
+			accept:   []string{"orion", "ОРИОН"},
+			reject:   []string{"orio"},
+		},
+		{
+			name:     "tolerance",
+			solution: "9.81",
+			match:    AnswerMatch{Tolerance: 0.05},
+			accept:   []string{"9.8", "9.85"},
+			reject:   []string{"9.9", "nine"},
+		},
+		{
+			name:     "regex",
+			solution: "1010",
+			match:    AnswerMatch{Regex: `0b?1010`},
+			accept:   []string{"1010", "0b1010", " 0B1010 "},
+			reject:   []string{"0b10101"},
+		},
+		{
+			name:     "unordered",
+			solution: "A, T, G",
+			match:    AnswerMatch{Unordered: true},
+			accept:   []string{"g t a", "T;A;G"},
+			reject:   []string{"a t", "a t g c"},
+		},
+	}
+	for _, tt := range tests {
+		checker, err := newAnswerChecker(tt.solution, tt.answers, tt.match)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		for _, answer := range tt.accept {
+			if !checker.Check(answer) {
+				t.Errorf("%s: %q was rejected", tt.name, answer)
+			}
+		}
+		for _, answer := range tt.reject {
+			if checker.Check(answer) {
+				t.Errorf("%s: %q was accepted", tt.name, answer)
+			}
+		}
+	}
+}
+
+func TestAnswerCheckerErrors(t *testing.T) {
+	for _, match := range []AnswerMatch{
+		{Normalize: []string{"emoji"}},
+		{Regex: "("},
+		{Tolerance: -1},
+	} {
+		if _, err := newAnswerChecker("42", nil, match); err == nil {
+			t.Errorf("%+v was accepted", match)
+		}
+	}
+}
+
+func TestCheckAnswerWithoutChecker(t *testing.T) {
+	quest := &Quest{Solution: "Вега"}
+	if !quest.checkAnswer(" вега ") || quest.checkAnswer("вег") {
+		t.Error("a quest without a checker does not compare answers case-insensitively")
+	}
+}
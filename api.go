@@ -0,0 +1,269 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// API serves game sessions over HTTP with JSON bodies:
+//
+//	POST   /sessions                 create a session: {"seed":42,"mode":"normal"}
+//	GET    /sessions/{id}            the current state
+//	POST   /sessions/{id}/commands   run a ProtocolCommand
+//	DELETE /sessions/{id}            end a session
+//
+// Every session is a single player game with save slots of its own, which
+// are deleted with the session. Sessions nobody used for longer than the
+// TTL expire, and no more than maxSessions are live at once.
+type API struct {
+	cfg         GameConfig
+	ttl         time.Duration
+	maxSessions int
+
+	mu       sync.Mutex
+	sessions map[string]*apiSession
+}
+
+type apiSession struct {
+	mu       sync.Mutex // one command at a time per game
+	game     *Game
+	lastUsed time.Time
+}
+
+// maxRequestBody is the largest request body the API reads.
+const maxRequestBody = 64 << 10
+
+// SessionResponse is a ProtocolResponse for a session, with the session ID
+// and when it expires unless used again.
+type SessionResponse struct {
+	ID        string    `json:"id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	ProtocolResponse
+}
+
+// sessionRequest is the body of POST /sessions. Both fields are optional.
+type sessionRequest struct {
+	Seed int64  `json:"seed"`
+	Mode string `json:"mode"`
+}
+
+// NewAPI returns an API whose games are created from cfg. Sessions expire
+// ttl after their last request; cfg.Clock.Now also drives the expiry. New
+// sessions are refused while maxSessions are live, unless it is 0.
+func NewAPI(cfg GameConfig, ttl time.Duration, maxSessions int) *API {
+	if cfg.Clock.Now == nil {
+		cfg.Clock.Now = time.Now
+	}
+	return &API{cfg: cfg, ttl: ttl, maxSessions: maxSessions, sessions: make(map[string]*apiSession)}
+}
+
+// ServeHTTP routes a request to its endpoint.
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "sessions":
+		a.allow(w, r, http.MethodPost, a.create)
+	case len(parts) == 2 && parts[0] == "sessions":
+		switch r.Method {
+		case http.MethodGet:
+			a.withSession(w, parts[1], a.get)
+		case http.MethodDelete:
+			a.remove(w, parts[1])
+		default:
+			w.Header().Set("Allow", "GET, DELETE")
+			writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("%s is not allowed here", r.Method))
+		}
+	case len(parts) == 3 && parts[0] == "sessions" && parts[2] == "commands":
+		a.allow(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+			a.withSession(w, parts[1], func(s *apiSession) (int, ProtocolResponse) {
+				return a.command(r, s)
+			})
+		})
+	default:
+		writeError(w, http.StatusNotFound, "no such endpoint")
+	}
+}
+
+// allow runs handler if the request uses method.
+func (a *API) allow(w http.ResponseWriter, r *http.Request, method string, handler http.HandlerFunc) {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("%s is not allowed here", r.Method))
+		return
+	}
+	handler(w, r)
+}
+
+func (a *API) create(w http.ResponseWriter, r *http.Request) {
+	var req sessionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, bodyErrorStatus(err), fmt.Sprintf("invalid session request: %v", err))
+			return
+		}
+	}
+	if req.Mode == "" {
+		req.Mode = defaultMode
+	}
+	if _, ok := findMode(req.Mode); !ok {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown mode %q: use %s", req.Mode, modeNames()))
+		return
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	cfg := a.cfg
+	cfg.Seed = req.Seed
+	cfg.Mode = req.Mode
+	cfg.SaveDir = a.sessionSaveDir(id)
+	g := NewGame(cfg)
+	s := &apiSession{game: g, lastUsed: a.cfg.Clock.Now()}
+
+	a.mu.Lock()
+	a.expire()
+	if a.maxSessions > 0 && len(a.sessions) >= a.maxSessions {
+		a.mu.Unlock()
+		writeError(w, http.StatusServiceUnavailable, "too many sessions, try again later")
+		return
+	}
+	a.sessions[id] = s
+	a.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusCreated, SessionResponse{ID: id, ExpiresAt: s.lastUsed.Add(a.ttl), ProtocolResponse: g.HandleProtocol(ProtocolCommand{Cmd: "look"})})
+}
+
+// get reports the state after letting the game clock catch up.
+func (a *API) get(s *apiSession) (int, ProtocolResponse) {
+	g := s.game
+	events := contentEvents(g.Tick().Events)
+	if events == nil {
+		events = []Event{}
+	}
+	return http.StatusOK, ProtocolResponse{
+		OK:      true,
+		Events:  events,
+		Outcome: g.outcome.String(),
+		Prompt:  g.NextPrompt(),
+		State:   g.state(),
+	}
+}
+
+func (a *API) command(r *http.Request, s *apiSession) (int, ProtocolResponse) {
+	var c ProtocolCommand
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		_, resp := a.get(s)
+		resp.OK = false
+		resp.Error = fmt.Sprintf("invalid command: %v", err)
+		return bodyErrorStatus(err), resp
+	}
+	// Commands the game rejects are still answered with the state, so the
+	// client can tell what went wrong from the response body
+	return http.StatusOK, s.game.HandleProtocol(c)
+}
+
+func (a *API) remove(w http.ResponseWriter, id string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.expire()
+	if _, ok := a.sessions[id]; !ok {
+		writeError(w, http.StatusNotFound, "session not found or expired")
+		return
+	}
+	a.drop(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// withSession runs handler on a live session and writes its response.
+func (a *API) withSession(w http.ResponseWriter, id string, handler func(*apiSession) (int, ProtocolResponse)) {
+	a.mu.Lock()
+	a.expire()
+	s, ok := a.sessions[id]
+	a.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "session not found or expired")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status, resp := handler(s)
+	s.lastUsed = a.cfg.Clock.Now()
+	writeJSON(w, status, SessionResponse{ID: id, ExpiresAt: s.lastUsed.Add(a.ttl), ProtocolResponse: resp})
+}
+
+// expire drops sessions unused for longer than the TTL. a.mu must be held.
+func (a *API) expire() {
+	now := a.cfg.Clock.Now()
+	for id, s := range a.sessions {
+		if s.mu.TryLock() {
+			if now.Sub(s.lastUsed) > a.ttl {
+				a.drop(id)
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// drop ends a session and deletes its saves. a.mu must be held.
+func (a *API) drop(id string) {
+	delete(a.sessions, id)
+	os.RemoveAll(a.sessionSaveDir(id))
+}
+
+// sessionSaveDir is the directory of the save slots of session id, so
+// sessions never share or overwrite each other's saves.
+func (a *API) sessionSaveDir(id string) string {
+	base := a.cfg.SaveDir
+	if base == "" {
+		base = defaultSaveDir
+	}
+	return filepath.Join(base, "sessions", id)
+}
+
+// bodyErrorStatus is the status for a request body that could not be read.
+func bodyErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not create a session ID: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}{Error: msg})
+}
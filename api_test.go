@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// apiReply is the part of a SessionResponse the tests read.
+type apiReply struct {
+	ID     string    `json:"id"`
+	OK     bool      `json:"ok"`
+	Error  string    `json:"error"`
+	Events []Event   `json:"events"`
+	State  GameState `json:"state"`
+}
+
+// newTestAPI serves sessions of test games that expire after ttl.
+func newTestAPI(t *testing.T, ttl time.Duration) (*httptest.Server, *testClock) {
+	return newLimitedTestAPI(t, ttl, 0)
+}
+
+// newLimitedTestAPI is newTestAPI with at most maxSessions live sessions.
+func newLimitedTestAPI(t *testing.T, ttl time.Duration, maxSessions int) (*httptest.Server, *testClock) {
+	t.Helper()
+	cfg, tc := testConfig(t, "", 0)
+	srv := httptest.NewServer(NewAPI(cfg, ttl, maxSessions))
+	t.Cleanup(srv.Close)
+	return srv, tc
+}
+
+// call sends a request with body, if any, and decodes the response into
+// out, if given. It returns the status code.
+func call(t *testing.T, method, url, body string, out interface{}) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: %v", method, url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// createSession starts a session and returns its URL.
+func createSession(t *testing.T, srv *httptest.Server, body string) string {
+	t.Helper()
+	var created apiReply
+	if status := call(t, http.MethodPost, srv.URL+"/sessions", body, &created); status != http.StatusCreated {
+		t.Fatalf("POST /sessions: status %d", status)
+	}
+	return srv.URL + "/sessions/" + created.ID
+}
+
+func TestAPICreateAndCommand(t *testing.T) {
+	srv, _ := newTestAPI(t, time.Hour)
+
+	var created apiReply
+	if status := call(t, http.MethodPost, srv.URL+"/sessions", `{"seed":42,"mode":"hardcore"}`, &created); status != http.StatusCreated {
+		t.Fatalf("POST /sessions: status %d", status)
+	}
+	if created.ID == "" || created.State.Stats.Seed != 42 || created.State.Stats.Mode != "hardcore" {
+		t.Fatalf("created %+v, want a hardcore session of seed 42", created)
+	}
+	session := srv.URL + "/sessions/" + created.ID
+
+	var moved apiReply
+	if status := call(t, http.MethodPost, session+"/commands", `{"cmd":"go","direction":"north"}`, &moved); status != http.StatusOK {
+		t.Fatalf("POST commands: status %d", status)
+	}
+	if !moved.OK || moved.State.Room.Name == created.State.Room.Name {
+		t.Errorf("go north left the player in %q", moved.State.Room.Name)
+	}
+
+	var state apiReply
+	if status := call(t, http.MethodGet, session, "", &state); status != http.StatusOK || state.State.Room.Name != moved.State.Room.Name {
+		t.Errorf("GET: status %d, room %q", status, state.State.Room.Name)
+	}
+
+	var rejected apiReply
+	if status := call(t, http.MethodPost, session+"/commands", `{"cmd":"dance"}`, &rejected); status != http.StatusOK || rejected.OK || rejected.Error == "" {
+		t.Errorf("unknown command: status %d, %+v", status, rejected)
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	srv, _ := newTestAPI(t, time.Hour)
+	session := createSession(t, srv, "")
+	huge := `{"cmd":"raw","input":"` + strings.Repeat("x", maxRequestBody) + `"}`
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+		status int
+	}{
+		{"unknown session", http.MethodGet, srv.URL + "/sessions/nope", "", http.StatusNotFound},
+		{"unknown endpoint", http.MethodGet, srv.URL + "/games", "", http.StatusNotFound},
+		{"wrong method", http.MethodPut, session, "", http.StatusMethodNotAllowed},
+		{"unknown mode", http.MethodPost, srv.URL + "/sessions", `{"mode":"nightmare"}`, http.StatusBadRequest},
+		{"invalid command", http.MethodPost, session + "/commands", `{"cmd":`, http.StatusBadRequest},
+		{"huge command", http.MethodPost, session + "/commands", huge, http.StatusRequestEntityTooLarge},
+		{"huge session request", http.MethodPost, srv.URL + "/sessions", `{"mode":"` + strings.Repeat("x", maxRequestBody) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp struct {
+				OK    bool   `json:"ok"`
+				Error string `json:"error"`
+			}
+			if status := call(t, tt.method, tt.url, tt.body, &resp); status != tt.status {
+				t.Errorf("status %d, want %d", status, tt.status)
+			}
+			if resp.OK || resp.Error == "" {
+				t.Errorf("response %+v, want an error", resp)
+			}
+		})
+	}
+}
+
+func TestAPISessionsExpire(t *testing.T) {
+	srv, tc := newTestAPI(t, 10*time.Minute)
+	session := createSession(t, srv, "")
+
+	tc.Advance(9 * time.Minute)
+	if status := call(t, http.MethodGet, session, "", nil); status != http.StatusOK {
+		t.Fatalf("GET before the TTL: status %d", status)
+	}
+	// Using the session keeps it alive
+	tc.Advance(9 * time.Minute)
+	if status := call(t, http.MethodGet, session, "", nil); status != http.StatusOK {
+		t.Fatalf("GET after a renewal: status %d", status)
+	}
+	tc.Advance(11 * time.Minute)
+	if status := call(t, http.MethodGet, session, "", nil); status != http.StatusNotFound {
+		t.Errorf("GET after the TTL: status %d, want 404", status)
+	}
+}
+
+func TestAPISessionLimit(t *testing.T) {
+	srv, tc := newLimitedTestAPI(t, 10*time.Minute, 2)
+	first := createSession(t, srv, "")
+	createSession(t, srv, "")
+	if status := call(t, http.MethodPost, srv.URL+"/sessions", "", nil); status != http.StatusServiceUnavailable {
+		t.Fatalf("POST over the limit: status %d, want 503", status)
+	}
+	// Ended and expired sessions make room again
+	call(t, http.MethodDelete, first, "", nil)
+	createSession(t, srv, "")
+	tc.Advance(11 * time.Minute)
+	createSession(t, srv, "")
+}
+
+func TestAPIDelete(t *testing.T) {
+	srv, _ := newTestAPI(t, time.Hour)
+	session := createSession(t, srv, "")
+	if status := call(t, http.MethodDelete, session, "", nil); status != http.StatusNoContent {
+		t.Fatalf("DELETE: status %d", status)
+	}
+	if status := call(t, http.MethodDelete, session, "", nil); status != http.StatusNotFound {
+		t.Errorf("second DELETE: status %d, want 404", status)
+	}
+}
+
+func TestAPISessionsKeepTheirOwnSaves(t *testing.T) {
+	srv, _ := newTestAPI(t, time.Hour)
+	first := createSession(t, srv, `{"seed":1}`)
+	second := createSession(t, srv, `{"seed":2}`)
+
+	var resp apiReply
+	call(t, http.MethodPost, first+"/commands", `{"cmd":"save","slot":"shared"}`, nil)
+	call(t, http.MethodPost, second+"/commands", `{"cmd":"load","slot":"shared"}`, &resp)
+	if resp.State.Stats.Seed != 2 {
+		t.Errorf("the second session loaded the save of the first")
+	}
+
+	call(t, http.MethodPost, first+"/commands", `{"cmd":"load","slot":"shared"}`, &resp)
+	if resp.State.Stats.Seed != 1 {
+		t.Errorf("the first session could not load its own save: %v", resp.Events)
+	}
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultQuestPack is the built-in quest catalog shipped with the game.
+//
+//go:embed quests/*.json
+var defaultQuestPack embed.FS
+
+// categoryKeys maps the category names used in quest files to categories.
+var categoryKeys = map[string]QuestCategory{
+	"hacker":       HackerQuest,
+	"engineering":  EngineeringQuest,
+	"astronomical": AstronomicalQuest,
+	"biological":   BiologicalQuest,
+	"physical":     PhysicalQuest,
+}
+
+// MarshalText writes a category under its quest file name.
+func (c QuestCategory) MarshalText() ([]byte, error) {
+	for key, category := range categoryKeys {
+		if category == c {
+			return []byte(key), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown quest category %d", int(c))
+}
+
+// UnmarshalText reads a category from its quest file name.
+func (c *QuestCategory) UnmarshalText(text []byte) error {
+	category, ok := categoryKeys[string(text)]
+	if !ok {
+		return fmt.Errorf("unknown quest category %q", text)
+	}
+	*c = category
+	return nil
+}
+
+// questDef is a quest as written in a quest pack file.
+type questDef struct {
+	ID           int         `json:"id"`
+	Name         string      `json:"name"`
+	Description  string      `json:"description"`
+	Category     string      `json:"category"`
+	Difficulty   int         `json:"difficulty"`
+	TimeLimit    string      `json:"time_limit"`
+	Reward       string      `json:"reward"`
+	Requirements []string    `json:"requirements"`
+	Generator    string      `json:"generator"`
+	Solution     string      `json:"solution"`
+	Answers      []string    `json:"answers"`
+	Match        AnswerMatch `json:"match"`
+	ASCII        []string    `json:"ascii"`
+	Hints        []string    `json:"hints"`
+	Example      string      `json:"example"`
+	Coop         *coopDef    `json:"coop"`
+}
+
+// coopDef is the "coop" object of a quest definition.
+type coopDef struct {
+	Players int    `json:"players"`
+	Window  string `json:"window"`
+}
+
+// LoadError describes a problem at a specific place in a data file.
+type LoadError struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e LoadError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Msg)
+}
+
+// LoadErrors collects every problem found while loading data files.
+type LoadErrors []LoadError
+
+func (e LoadErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lineAt returns the 1-based line number of offset in data.
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// skipSeparators advances offset past whitespace and commas so it points at
+// the first byte of the next JSON value.
+func skipSeparators(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.ContainsRune(" \t\r\n,", rune(data[offset])) {
+		offset++
+	}
+	return offset
+}
+
+// questLoader accumulates quests from several files and validates them.
+type questLoader struct {
+	quests []*Quest
+	seen   map[int]LoadError // where each quest ID was first defined
+	errs   LoadErrors
+}
+
+func newQuestLoader() *questLoader {
+	return &questLoader{seen: make(map[int]LoadError)}
+}
+
+func (l *questLoader) errorf(file string, line int, format string, args ...interface{}) {
+	l.errs = append(l.errs, LoadError{File: file, Line: line, Msg: fmt.Sprintf(format, args...)})
+}
+
+// questParsers read quest files in formats other than JSON, by extension.
+var questParsers = map[string]func(data []byte) ([]rawQuest, error){
+	".yaml": parseYAMLQuests,
+	".yml":  parseYAMLQuests,
+	".toml": parseTOMLQuests,
+}
+
+// rawQuest is a quest read from a YAML or TOML file: its fields as JSON
+// values and the line it starts on.
+type rawQuest struct {
+	line   int
+	fields map[string]interface{}
+}
+
+// lineError is a problem at line of a file that is not known yet.
+func lineError(line int, format string, args ...interface{}) error {
+	return LoadError{Line: line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// loadFS reads every quest file in dir of fsys. Errors name files relative
+// to label.
+func (l *questLoader) loadFS(fsys fs.FS, dir, label string) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		l.errorf(label, 0, "%v", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := path.Ext(entry.Name())
+		parse, ok := questParsers[ext]
+		if !ok && ext != ".json" {
+			continue
+		}
+		name := filepath.Join(label, entry.Name())
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			l.errorf(name, 0, "%v", err)
+			continue
+		}
+		if ok {
+			l.loadParsed(name, data, parse)
+		} else {
+			l.loadFile(name, data)
+		}
+	}
+}
+
+// loadParsed reads a YAML or TOML quest file with parse. The quests are
+// decoded like JSON ones, so every format knows the same fields.
+func (l *questLoader) loadParsed(name string, data []byte, parse func([]byte) ([]rawQuest, error)) {
+	quests, err := parse(data)
+	if err != nil {
+		var loadErr LoadError
+		errors.As(err, &loadErr)
+		l.errorf(name, loadErr.Line, "%s", loadErr.Msg)
+		return
+	}
+
+	for _, raw := range quests {
+		encoded, err := json.Marshal(raw.fields)
+		if err != nil {
+			l.errorf(name, raw.line, "%v", err)
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(encoded))
+		dec.DisallowUnknownFields()
+		var def questDef
+		if err := dec.Decode(&def); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				l.errorf(name, raw.line, "field %s must be %s, not %s", typeErr.Field, typeErr.Type, typeErr.Value)
+			} else {
+				l.errorf(name, raw.line, "%v", err)
+			}
+			continue
+		}
+		if quest := l.validate(name, raw.line, def); quest != nil {
+			l.quests = append(l.quests, quest)
+		}
+	}
+}
+
+// loadFile parses one quest file holding a JSON array of quests.
+func (l *questLoader) loadFile(name string, data []byte) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		l.errorf(name, 1, "expected a JSON array of quests")
+		return
+	}
+
+	for dec.More() {
+		line := lineAt(data, skipSeparators(data, dec.InputOffset()))
+
+		var def questDef
+		if err := dec.Decode(&def); err != nil {
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			switch {
+			case errors.As(err, &syntaxErr):
+				l.errorf(name, lineAt(data, syntaxErr.Offset), "%v", err)
+				return
+			case errors.As(err, &typeErr):
+				l.errorf(name, lineAt(data, typeErr.Offset), "%v", err)
+			default:
+				l.errorf(name, line, "%v", err)
+			}
+			if dec.More() {
+				continue
+			}
+			return
+		}
+
+		if quest := l.validate(name, line, def); quest != nil {
+			l.quests = append(l.quests, quest)
+		}
+	}
+}
+
+// validate checks a quest definition and converts it to a Quest. It reports
+// every problem it finds and returns nil if there were any.
+func (l *questLoader) validate(file string, line int, def questDef) *Quest {
+	before := len(l.errs)
+
+	if def.ID <= 0 {
+		l.errorf(file, line, "quest ID must be a positive number, got %d", def.ID)
+	} else if first, dup := l.seen[def.ID]; dup {
+		l.errorf(file, line, "duplicate quest ID %d (first defined at %s:%d)", def.ID, first.File, first.Line)
+	} else {
+		l.seen[def.ID] = LoadError{File: file, Line: line}
+	}
+
+	if strings.TrimSpace(def.Name) == "" {
+		l.errorf(file, line, "quest %d has no name", def.ID)
+	}
+	if def.Generator != "" {
+		if _, ok := generators[def.Generator]; !ok {
+			l.errorf(file, line, "quest %d has unknown generator %q", def.ID, def.Generator)
+		}
+	} else if strings.TrimSpace(def.Solution) == "" {
+		l.errorf(file, line, "quest %d has no solution", def.ID)
+	}
+	if def.Difficulty < 1 || def.Difficulty > 5 {
+		l.errorf(file, line, "quest %d has difficulty %d, must be between 1 and 5", def.ID, def.Difficulty)
+	}
+
+	category, ok := categoryKeys[def.Category]
+	if !ok {
+		l.errorf(file, line, "quest %d has unknown category %q", def.ID, def.Category)
+	}
+
+	for _, spec := range def.Requirements {
+		req, err := parseRequirement(spec)
+		if err != nil {
+			l.errorf(file, line, "quest %d: %v", def.ID, err)
+		} else if req.Kind == "quest" && req.Value == def.ID {
+			l.errorf(file, line, "quest %d requires itself", def.ID)
+		}
+	}
+
+	timeLimit, err := time.ParseDuration(def.TimeLimit)
+	if err != nil || timeLimit <= 0 {
+		l.errorf(file, line, "quest %d has invalid time limit %q", def.ID, def.TimeLimit)
+	}
+
+	checker, err := newAnswerChecker(def.Solution, def.Answers, def.Match)
+	if err != nil {
+		l.errorf(file, line, "quest %d: %v", def.ID, err)
+	}
+
+	var coop *CoopRule
+	if def.Coop != nil {
+		window, err := time.ParseDuration(def.Coop.Window)
+		if err != nil || window <= 0 {
+			l.errorf(file, line, "quest %d has invalid co-op window %q", def.ID, def.Coop.Window)
+		}
+		if def.Coop.Players < 2 {
+			l.errorf(file, line, "quest %d needs at least 2 co-op players, got %d", def.ID, def.Coop.Players)
+		} else if def.Generator == "" && len(answerTokens(def.Solution)) < def.Coop.Players {
+			l.errorf(file, line, "quest %d: solution %q cannot be split between %d players", def.ID, def.Solution, def.Coop.Players)
+		}
+		coop = &CoopRule{Players: def.Coop.Players, Window: window}
+	}
+
+	if len(l.errs) > before {
+		return nil
+	}
+
+	return &Quest{
+		ID:           def.ID,
+		Name:         def.Name,
+		Description:  def.Description,
+		Category:     category,
+		Difficulty:   def.Difficulty,
+		TimeLimit:    timeLimit,
+		Reward:       def.Reward,
+		Requirements: def.Requirements,
+		Solution:     def.Solution,
+		ASCII:        asciiArt(def.ASCII),
+		Hints:        def.Hints,
+		Example:      def.Example,
+		Answers:      def.Answers,
+		Match:        def.Match,
+		Checker:      checker,
+		Generator:    def.Generator,
+		Coop:         coop,
+	}
+}
+
+// result returns the loaded quests sorted by ID, or every error found.
+func (l *questLoader) result() ([]*Quest, error) {
+	if len(l.errs) > 0 {
+		return nil, l.errs
+	}
+	sort.Slice(l.quests, func(i, j int) bool { return l.quests[i].ID < l.quests[j].ID })
+	return l.quests, nil
+}
+
+// LoadQuests loads the built-in quest pack plus every quest file in extraDir,
+// if one is given.
+func LoadQuests(extraDir string) ([]*Quest, error) {
+	l := newQuestLoader()
+	l.loadFS(defaultQuestPack, "quests", "quests")
+	if extraDir != "" {
+		l.loadFS(os.DirFS(extraDir), ".", extraDir)
+	}
+	return l.result()
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// questJSON returns a valid quest definition with the given ID and extra or
+// overriding fields, written as JSON object members.
+func questJSON(id, fields string) string {
+	def := `{"id": ` + id + `, "name": "test", "category": "hacker", "difficulty": 2, "time_limit": "5m", "solution": "42"`
+	if fields != "" {
+		def += ", " + fields
+	}
+	return def + "}"
+}
+
+// loadPack writes files into a quest pack directory and loads it together
+// with the built-in quests.
+func loadPack(t *testing.T, files map[string]string) (string, []*Quest, error) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	quests, err := LoadQuests(dir)
+	return dir, quests, err
+}
+
+func TestBuiltInQuestsLoad(t *testing.T) {
+	quests, err := LoadQuests("")
+	if err != nil {
+		t.Fatalf("LoadQuests: %v", err)
+	}
+	for i := 1; i < len(quests); i++ {
+		if quests[i-1].ID >= quests[i].ID {
+			t.Fatalf("quests are not sorted by ID: %d before %d", quests[i-1].ID, quests[i].ID)
+		}
+	}
+}
+
+func TestQuestPackAddsQuests(t *testing.T) {
+	builtIn, err := LoadQuests("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, quests, err := loadPack(t, map[string]string{
+		"extra.json": "[\n" + questJSON("900", "") + ",\n" + questJSON("901", `"answers": ["сорок два"]`) + "\n]",
+		"notes.txt":  "not a quest file",
+	})
+	if err != nil {
+		t.Fatalf("LoadQuests: %v", err)
+	}
+	if len(quests) != len(builtIn)+2 || quests[len(quests)-1].ID != 901 {
+		t.Fatalf("%d quests, want the %d built-in ones plus 900 and 901", len(quests), len(builtIn))
+	}
+	if !quests[len(quests)-1].Checker.Check("сорок два") {
+		t.Error("the alternative answer of quest 901 is not accepted")
+	}
+}
+
+func TestQuestPackFormats(t *testing.T) {
+	const yamlQuest = `---
+- id: 900
+  name: "Тест: YAML"   # quoted, as it holds a colon
+  description: >
+    Folded text
+    on two lines
+  category: hacker
+  difficulty: 2
+  time_limit: 5m
+  requirements: [quest:1, 'item:key']
+  solution: 42 17
+  match:
+    unordered: true
+  ascii:
+    - "  ╔══╗"
+    - '  ╚══╝'
+  hints:
+  - first
+  - second
+  example: |
+    line one
+    line two
+  coop: {players: 2, window: 30s}
+`
+	const tomlQuest = `# the same quest in TOML
+[[quest]]
+id = 900
+name = "Тест: YAML"
+description = "Folded text on two lines\n"
+category = 'hacker'
+difficulty = 2
+time_limit = "5m"
+requirements = ["quest:1", "item:key"]
+solution = "42 17"
+match = { unordered = true }
+ascii = [
+  "  ╔══╗",
+  '  ╚══╝',  # trailing comma
+]
+hints = ["first", "second"]
+example = """
+line one
+line two
+"""
+
+[quest.coop]
+players = 2
+window = "30s"
+`
+	for name, data := range map[string]string{"a.yaml": yamlQuest, "a.toml": tomlQuest} {
+		t.Run(name, func(t *testing.T) {
+			_, quests, err := loadPack(t, map[string]string{name: data})
+			if err != nil {
+				t.Fatalf("LoadQuests: %v", err)
+			}
+			q := quests[len(quests)-1]
+			if q.ID != 900 || q.Name != "Тест: YAML" || q.Category != HackerQuest || q.Difficulty != 2 || q.TimeLimit != 5*time.Minute {
+				t.Errorf("quest %d %q, category %v, difficulty %d, time limit %s", q.ID, q.Name, q.Category, q.Difficulty, q.TimeLimit)
+			}
+			if q.Description != "Folded text on two lines\n" || q.Example != "line one\nline two\n" {
+				t.Errorf("description %q, example %q", q.Description, q.Example)
+			}
+			if strings.Join(q.Requirements, ",") != "quest:1,item:key" || strings.Join(q.Hints, ",") != "first,second" {
+				t.Errorf("requirements %q, hints %q", q.Requirements, q.Hints)
+			}
+			if q.ASCII != "\n  ╔══╗\n  ╚══╝" {
+				t.Errorf("ascii %q", q.ASCII)
+			}
+			if q.Solution != "42 17" || !q.Match.Unordered || !q.Checker.Check("17 42") {
+				t.Errorf("solution %q, match %+v", q.Solution, q.Match)
+			}
+			if q.Coop == nil || q.Coop.Players != 2 || q.Coop.Window != 30*time.Second {
+				t.Errorf("co-op rule %+v", q.Coop)
+			}
+		})
+	}
+}
+
+func TestQuestPackErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string // file, line and message of every error
+	}{
+		{
+			name:  "duplicate ID",
+			files: map[string]string{"a.json": "[\n" + questJSON("900", "") + ",\n" + questJSON("900", "") + "\n]"},
+			want:  []string{"a.json:3: duplicate quest ID 900 (first defined at "},
+		},
+		{
+			name:  "clashes with a built-in quest",
+			files: map[string]string{"a.json": "[\n" + questJSON("1", "") + "\n]"},
+			want:  []string{"a.json:2: duplicate quest ID 1 (first defined at quests"},
+		},
+		{
+			name:  "missing solution",
+			files: map[string]string{"a.json": "[\n" + questJSON("900", `"solution": ""`) + "\n]"},
+			want:  []string{"a.json:2: quest 900 has no solution"},
+		},
+		{
+			name:  "difficulty out of range",
+			files: map[string]string{"a.json": "[\n" + questJSON("900", `"difficulty": 6`) + "\n]"},
+			want:  []string{"a.json:2: quest 900 has difficulty 6, must be between 1 and 5"},
+		},
+		{
+			name:  "unknown category",
+			files: map[string]string{"a.json": "[\n" + questJSON("900", `"category": "magic"`) + "\n]"},
+			want:  []string{`a.json:2: quest 900 has unknown category "magic"`},
+		},
+		{
+			name:  "unknown field",
+			files: map[string]string{"a.json": "[\n" + questJSON("900", `"answer": "42"`) + "\n]"},
+			want:  []string{`a.json:2: json: unknown field "answer"`},
+		},
+		{
+			name:  "not an array",
+			files: map[string]string{"a.json": questJSON("900", "")},
+			want:  []string{"a.json:1: expected a JSON array of quests"},
+		},
+		{
+			name:  "every problem of a quest",
+			files: map[string]string{"a.json": "[\n" + questJSON("900", `"name": " ", "time_limit": "soon"`) + "\n]"},
+			want: []string{
+				"a.json:2: quest 900 has no name",
+				`a.json:2: quest 900 has invalid time limit "soon"`,
+			},
+		},
+		{
+			name:  "YAML syntax",
+			files: map[string]string{"a.yaml": "- id: 900\n  name: test\n   solution: 42\n"},
+			want:  []string{"a.yaml:3: unexpected indentation"},
+		},
+		{
+			name:  "YAML quest",
+			files: map[string]string{"a.yaml": "# extra quests\n- id: 900\n  name: test\n  category: magic\n  difficulty: 2\n  time_limit: 5m\n  solution: 42\n"},
+			want:  []string{`a.yaml:2: quest 900 has unknown category "magic"`},
+		},
+		{
+			name:  "YAML field type",
+			files: map[string]string{"a.yaml": "- id: 900\n  difficulty: hard\n"},
+			want:  []string{"a.yaml:1: field difficulty must be int, not string"},
+		},
+		{
+			name:  "TOML syntax",
+			files: map[string]string{"a.toml": "[[quest]]\nid = 900\nname = \"test\n"},
+			want:  []string{"a.toml:3: unterminated string"},
+		},
+		{
+			name:  "TOML quest",
+			files: map[string]string{"a.toml": "[[quest]]\nid = 900\n\n[[quest]]\nid = 901\nanswer = \"42\"\n"},
+			want: []string{
+				"a.toml:1: quest 900 has no name",
+				"a.toml:1: quest 900 has no solution",
+				"a.toml:1: quest 900 has difficulty 0",
+				`a.toml:1: quest 900 has unknown category ""`,
+				`a.toml:1: quest 900 has invalid time limit ""`,
+				`a.toml:4: json: unknown field "answer"`,
+			},
+		},
+		{
+			name:  "TOML without quest tables",
+			files: map[string]string{"a.toml": "id = 900\n"},
+			want:  []string{"a.toml:1: every quest starts with a [[quest]] header"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, quests, err := loadPack(t, tt.files)
+			if quests != nil {
+				t.Error("quests were returned along with errors")
+			}
+			var errs LoadErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("error %v is not a LoadErrors", err)
+			}
+			if len(errs) != len(tt.want) {
+				t.Fatalf("errors %v, want %d", errs, len(tt.want))
+			}
+			for i, want := range tt.want {
+				want = filepath.Join(dir, want)
+				if got := errs[i].Error(); !strings.HasPrefix(got, want) {
+					t.Errorf("error %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// ClockConfig controls the global countdown.
+type ClockConfig struct {
+	Budget       time.Duration    // total time to escape
+	PauseInMenus bool             // stop the clock while a menu screen is open
+	MoveCost     time.Duration    // extra time spent walking to another room
+	TakeCost     time.Duration    // extra time spent picking something up
+	AttemptCost  time.Duration    // extra time spent on every quest answer
+	Warnings     []time.Duration  // remaining times that trigger a warning
+	Now          func() time.Time // clock source, time.Now if nil
+}
+
+// DefaultClockConfig returns the clock used by a normal game.
+func DefaultClockConfig() ClockConfig {
+	return ClockConfig{
+		Budget:       60 * time.Minute,
+		PauseInMenus: true,
+		MoveCost:     30 * time.Second,
+		TakeCost:     10 * time.Second,
+		AttemptCost:  1 * time.Minute,
+		Warnings:     []time.Duration{10 * time.Minute, 5 * time.Minute, 1 * time.Minute},
+	}
+}
+
+// gameClock tracks how much of the time budget has been used.
+type gameClock struct {
+	paused     time.Duration // wall time spent in menus
+	penalty    time.Duration // time charged for actions
+	menuOpened time.Time     // when the open menu was shown, zero if none
+}
+
+// now returns the time of the step being processed. Every part of a
+// command sees the same instant, so recorded games replay exactly.
+func (g *Game) now() time.Time {
+	return g.stepTime
+}
+
+// beginStep fixes the time of the step about to be processed.
+func (g *Game) beginStep() {
+	g.stepTime = g.Config.Clock.Now()
+}
+
+// elapsed returns the wall time played, not counting paused menus.
+func (g *Game) elapsed() time.Duration {
+	now := g.now()
+	elapsed := now.Sub(g.GameStart) - g.clock.paused
+	if !g.clock.menuOpened.IsZero() {
+		elapsed -= now.Sub(g.clock.menuOpened)
+	}
+	return elapsed
+}
+
+// syncClock recomputes PlayerStats.TimeLeft from the clock.
+func (g *Game) syncClock() {
+	g.Player.Stats.TimeLeft = g.budget() - g.elapsed() - g.clock.penalty
+}
+
+// budget returns the total time of the game's mode.
+func (g *Game) budget() time.Duration {
+	return g.Config.Clock.Budget * time.Duration(g.mode().BudgetPercent) / 100
+}
+
+// chargeTime uses up d of the remaining time.
+func (g *Game) chargeTime(d time.Duration) {
+	g.clock.penalty += d
+	g.syncClock()
+}
+
+// openMenu stops the clock, if configured, until the menu is closed.
+func (g *Game) openMenu() {
+	g.menuOpen = true
+	if g.Config.Clock.PauseInMenus {
+		g.clock.menuOpened = g.now()
+	}
+}
+
+// closeMenu restarts the clock after a menu.
+func (g *Game) closeMenu() {
+	g.menuOpen = false
+	if !g.clock.menuOpened.IsZero() {
+		g.clock.paused += g.now().Sub(g.clock.menuOpened)
+		g.clock.menuOpened = time.Time{}
+	}
+}
+
+// timeWarning returns a warning for the tightest threshold already passed.
+func (g *Game) timeWarning() string {
+	left := g.Player.Stats.TimeLeft
+	var crossed time.Duration
+	for _, threshold := range g.Config.Clock.Warnings {
+		if left <= threshold && (crossed == 0 || threshold < crossed) {
+			crossed = threshold
+		}
+	}
+	if crossed == 0 {
+		return ""
+	}
+	return fmt.Sprintf("⏰ Less than %s left! Hurry!", formatMinutes(crossed))
+}
+
+// formatMinutes prints whole minutes as "5 minutes" and anything else as a
+// plain duration.
+func formatMinutes(d time.Duration) string {
+	switch {
+	case d == time.Minute:
+		return "1 minute"
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%d minutes", d/time.Minute)
+	default:
+		return d.String()
+	}
+}
+
+// Answering within TimeLimit/speedBonusFraction earns
+// speedBonusPerDifficulty extra skill points per difficulty level.
+const (
+	speedBonusFraction      = 2
+	speedBonusPerDifficulty = 2
+)
+
+// countdownMarks are the remaining quest times announced while the player
+// is answering.
+var countdownMarks = []time.Duration{time.Minute, 30 * time.Second, 10 * time.Second}
+
+// questAttempt tracks the quest currently being answered.
+type questAttempt struct {
+	started   time.Time
+	announced time.Duration // last countdown mark shown, zero if none
+}
+
+// questTimeLeft returns how long the player still has to answer the
+// active quest.
+func (g *Game) questTimeLeft() time.Duration {
+	if g.activeQuest == nil {
+		return 0
+	}
+	return g.timeLimit(g.activeQuest) - g.now().Sub(g.attempt.started)
+}
+
+// Tick advances the clock without any input. Frontends call it regularly so
+// a quest attempt fails as soon as its time limit runs out and the game
+// ends when the global timer hits zero.
+func (g *Game) Tick() Result {
+	defer g.lock()()
+	g.events = nil
+	g.beginStep()
+	g.syncClock()
+
+	if g.activeQuest != nil && g.mode().EnforceTimeLimits {
+		left := g.questTimeLeft()
+		if left <= 0 {
+			g.failQuest("⏰ Time's up! The quest's time limit ran out.")
+		} else {
+			// Announce only the tightest mark passed since the last tick
+			var crossed time.Duration
+			for _, mark := range countdownMarks {
+				if left <= mark {
+					crossed = mark
+				}
+			}
+			if crossed != 0 && (g.attempt.announced == 0 || crossed < g.attempt.announced) {
+				g.attempt.announced = crossed
+				g.message(EventWarning, fmt.Sprintf("⏳ %s left to answer!", formatMinutes(crossed)))
+			}
+		}
+	}
+
+	g.checkEnd()
+	return Result{Events: g.events, Outcome: g.outcome}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeLeftFollowsTheClock(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		commands []string
+		wait     time.Duration
+		want     time.Duration
+	}{
+		{"waiting", "normal", nil, 10 * time.Minute, 50 * time.Minute},
+		{"walking", "normal", []string{"go north"}, 0, 60*time.Minute - 30*time.Second},
+		{"hardcore has half", "hardcore", nil, time.Minute, 29 * time.Minute},
+		{"menus stop the clock", "normal", []string{"help"}, 5 * time.Minute, 60 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, clock := newTestGame(t, tt.mode, 7)
+			run(g, tt.commands...)
+			clock.Advance(tt.wait)
+			run(g, "look")
+			if g.Player.Stats.TimeLeft != tt.want {
+				t.Errorf("%v left, want %v", g.Player.Stats.TimeLeft, tt.want)
+			}
+		})
+	}
+}
+
+func TestMenusCanKeepTheClockRunning(t *testing.T) {
+	cfg, clock := testConfig(t, "normal", 7)
+	cfg.Clock.PauseInMenus = false
+	g := NewGame(cfg)
+	run(g, "help")
+	clock.Advance(5 * time.Minute)
+	run(g, "look")
+	if g.Player.Stats.TimeLeft != 55*time.Minute {
+		t.Errorf("%v left, want 55m0s", g.Player.Stats.TimeLeft)
+	}
+}
+
+func TestTimeWarning(t *testing.T) {
+	tests := []struct {
+		left time.Duration
+		want string
+	}{
+		{20 * time.Minute, ""},
+		{10 * time.Minute, "Less than 10 minutes left"},
+		{4 * time.Minute, "Less than 5 minutes left"},
+		{30 * time.Second, "Less than 1 minute left"},
+	}
+	g, _ := newTestGame(t, "normal", 7)
+	for _, tt := range tests {
+		g.Player.Stats.TimeLeft = tt.left
+		got := g.timeWarning()
+		if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+			t.Errorf("%v left: warning %q, want %q", tt.left, got, tt.want)
+		}
+	}
+}
+
+func TestRunningOutOfTimeLosesTheGame(t *testing.T) {
+	g, clock := newTestGame(t, "normal", 7)
+	clock.Advance(59 * time.Minute)
+	if res := g.Tick(); res.Outcome != OutcomeContinue {
+		t.Fatalf("outcome %s with a minute left", res.Outcome)
+	}
+	clock.Advance(time.Minute)
+	if res := g.Tick(); res.Outcome != OutcomeLost {
+		t.Errorf("outcome %s when time ran out", res.Outcome)
+	}
+}
+
+// startQuest starts the first quest of the player at its station, with its
+// requirements dropped.
+func startQuest(t *testing.T, g *Game) *Quest {
+	t.Helper()
+	quest := g.Player.Quests[0]
+	quest.Requirements = nil
+	if room, _ := g.questLocation(quest); room != nil {
+		g.Player.CurrentRoom = room
+	}
+	run(g, "start "+strconv.Itoa(quest.ID))
+	if g.activeQuest != quest {
+		t.Fatalf("quest %d did not start", quest.ID)
+	}
+	return quest
+}
+
+func TestQuestTimeLimit(t *testing.T) {
+	g, clock := newTestGame(t, "normal", 7)
+	quest := startQuest(t, g)
+	limit := g.timeLimit(quest)
+
+	clock.Advance(limit - 50*time.Second)
+	if out := eventText(g.Tick()); !strings.Contains(out, "1 minute left to answer") {
+		t.Errorf("no countdown with 50s left: %q", out)
+	}
+	if out := eventText(g.Tick()); strings.Contains(out, "left to answer") {
+		t.Errorf("the countdown repeated itself: %q", out)
+	}
+	clock.Advance(25 * time.Second)
+	if out := eventText(g.Tick()); !strings.Contains(out, "30s left to answer") {
+		t.Errorf("no countdown with 25s left: %q", out)
+	}
+
+	clock.Advance(25 * time.Second)
+	g.Tick()
+	if g.activeQuest != nil || quest.Solved || g.Player.WrongAttempts != 1 {
+		t.Errorf("the quest did not fail when its time ran out")
+	}
+}
+
+func TestLateAnswers(t *testing.T) {
+	tests := []struct {
+		mode   string
+		solved bool
+	}{
+		{"normal", false},
+		{"tutorial", true},
+	}
+	for _, tt := range tests {
+		g, clock := newTestGame(t, tt.mode, 7)
+		quest := startQuest(t, g)
+		clock.Advance(g.timeLimit(quest) + time.Second)
+		run(g, quest.Solution)
+		if quest.Solved != tt.solved {
+			t.Errorf("%s: solved %v after the time limit, want %v", tt.mode, quest.Solved, tt.solved)
+		}
+	}
+}
+
+func TestSpeedBonus(t *testing.T) {
+	tests := []struct {
+		name  string
+		wait  func(limit time.Duration) time.Duration
+		bonus bool
+	}{
+		{"right away", func(time.Duration) time.Duration { return 0 }, true},
+		{"at half the limit", func(limit time.Duration) time.Duration { return limit / 2 }, true},
+		{"after half the limit", func(limit time.Duration) time.Duration { return limit/2 + time.Second }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, clock := newTestGame(t, "normal", 7)
+			quest := startQuest(t, g)
+			xp := g.Player.Stats.XP
+			clock.Advance(tt.wait(g.timeLimit(quest)))
+			run(g, quest.Solution)
+
+			want := quest.Difficulty * 5
+			if tt.bonus {
+				want += quest.Difficulty * speedBonusPerDifficulty
+			}
+			if g.Player.Stats.XP-xp != want {
+				t.Errorf("%d XP, want %d", g.Player.Stats.XP-xp, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// CoopRule makes a quest cooperative: its solution is split into Players
+// parts, and different players must each enter one part within Window of
+// the first. Alone, outside multiplayer, the whole solution is entered at
+// once.
+type CoopRule struct {
+	Players int
+	Window  time.Duration
+}
+
+// coopAttempt is a co-op quest being solved in a shared world.
+type coopAttempt struct {
+	quest   *Quest        // the puzzle everyone works on
+	claims  map[int]*Game // part index -> player who entered it
+	started time.Time     // when the first part was entered
+}
+
+// answerTokens splits an answer into its words and numbers, ignoring case,
+// arrows and punctuation.
+func answerTokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// coopParts splits the solution of a co-op quest into one part per player,
+// in order, as evenly as possible.
+func coopParts(quest *Quest) [][]string {
+	tokens := answerTokens(quest.Solution)
+	n := quest.Coop.Players
+	parts := make([][]string, 0, n)
+	for i := 0; i < n; i++ {
+		parts = append(parts, tokens[i*len(tokens)/n:(i+1)*len(tokens)/n])
+	}
+	return parts
+}
+
+func coopView(quest *Quest) *CoopView {
+	view := &CoopView{Players: quest.Coop.Players, Window: quest.Coop.Window}
+	for _, part := range coopParts(quest) {
+		view.PartSizes = append(view.PartSizes, len(part))
+	}
+	return view
+}
+
+// coopOpen reports whether a co-op quest has been started by a player who
+// has it, so that others may join. Only such a player opens an attempt.
+func (g *Game) coopOpen(quest *Quest) bool {
+	if g.world == nil {
+		return false
+	}
+	_, ok := g.world.coop[quest.ID]
+	return ok
+}
+
+// coopAttempt returns the open attempt at a co-op quest, starting one with
+// the player's own puzzle if nobody is working on it. An attempt whose
+// window ran out starts over.
+func (g *Game) coopAttempt(quest *Quest) *coopAttempt {
+	w := g.world
+	attempt, ok := w.coop[quest.ID]
+	if !ok {
+		attempt = &coopAttempt{quest: quest, claims: make(map[int]*Game)}
+		w.coop[quest.ID] = attempt
+	}
+	if !attempt.started.IsZero() && g.now().Sub(attempt.started) > quest.Coop.Window {
+		for _, player := range attempt.claims {
+			if player != g {
+				g.tell(player, Event{Kind: EventWarning, Text: fmt.Sprintf("⏰ The co-op attempt at %s timed out; every part must be entered again.", quest.Name)})
+			}
+		}
+		attempt.claims = make(map[int]*Game)
+		attempt.started = time.Time{}
+	}
+	return attempt
+}
+
+// joinCoop returns the puzzle the player works on for a co-op quest, with
+// the parts entered so far.
+func (g *Game) joinCoop(quest *Quest) (*Quest, *CoopView) {
+	attempt := g.coopAttempt(quest)
+	view := coopView(attempt.quest)
+	for part := range coopParts(attempt.quest) {
+		name := ""
+		if player, ok := attempt.claims[part]; ok {
+			name = player.Player.Name
+		}
+		view.Claimed = append(view.Claimed, name)
+	}
+	return attempt.quest, view
+}
+
+// answerCoop enters the player's part of a co-op quest. The quest is solved
+// for everyone who entered a part once every part is in.
+func (g *Game) answerCoop(answer string) {
+	quest := g.activeQuest
+	attempt := g.coopAttempt(quest)
+	g.activeQuest = nil
+
+	for _, player := range attempt.claims {
+		if player == g {
+			g.message(EventWarning, "You have already entered your part; other players must enter the rest.")
+			g.pause(3 * time.Second)
+			g.Look()
+			return
+		}
+	}
+
+	tokens := answerTokens(answer)
+	part := -1
+	for i, want := range coopParts(quest) {
+		if _, claimed := attempt.claims[i]; !claimed && strings.Join(tokens, " ") == strings.Join(want, " ") {
+			part = i
+			break
+		}
+	}
+	if part < 0 {
+		g.activeQuest = quest
+		g.failQuest("That is not one of the missing parts. Try again.")
+		return
+	}
+
+	if attempt.started.IsZero() {
+		attempt.started = g.now()
+	}
+	attempt.claims[part] = g
+
+	missing := quest.Coop.Players - len(attempt.claims)
+	if missing > 0 {
+		left := (quest.Coop.Window - g.now().Sub(attempt.started)).Round(time.Second)
+		text := fmt.Sprintf("🤝 %s entered part %d of %s. %d more needed within %s!", g.Player.Name, part+1, quest.Name, missing, left)
+		for _, other := range g.world.players {
+			if other != g {
+				g.tell(other, Event{Kind: EventInfo, Text: text})
+			}
+		}
+		g.message(EventSuccess, fmt.Sprintf("🤝 Part %d accepted! Waiting for %d more players.", part+1, missing))
+		g.pause(3 * time.Second)
+		g.Look()
+		return
+	}
+
+	// Every part is in: the quest is solved for each participant
+	delete(g.world.coop, quest.ID)
+	var names []string
+	for _, player := range attempt.claims {
+		names = append(names, player.Player.Name)
+	}
+	sort.Strings(names)
+	for i, player := range attempt.claims {
+		player.Player.CoopCredit[quest.ID] = i + 1
+		if player == g {
+			continue
+		}
+		player.events = nil
+		player.completeCoop(quest.ID, names)
+		g.tell(player, player.events...)
+	}
+	g.completeCoop(quest.ID, names)
+	if g.outcome != OutcomeWon {
+		g.pause(3 * time.Second)
+		g.Look()
+	}
+}
+
+// completeCoop solves the player's own copy of a co-op quest.
+func (g *Game) completeCoop(questID int, team []string) {
+	for _, quest := range g.AllQuests {
+		if quest.ID == questID {
+			g.message(EventSuccess, fmt.Sprintf("🤝 Team effort with %s!", strings.Join(team, ", ")))
+			g.completeQuest(quest, false)
+			return
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCoopParts(t *testing.T) {
+	tests := []struct {
+		solution string
+		players  int
+		want     [][]string
+	}{
+		{"A→B→C→D", 2, [][]string{{"a", "b"}, {"c", "d"}}},
+		{"A→B→C→D→E", 2, [][]string{{"a", "b"}, {"c", "d", "e"}}},
+		{"1, 2, 3", 3, [][]string{{"1"}, {"2"}, {"3"}}},
+	}
+	for _, tt := range tests {
+		quest := &Quest{Solution: tt.solution, Coop: &CoopRule{Players: tt.players, Window: time.Minute}}
+		if got := coopParts(quest); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("coopParts(%q, %d) = %v, want %v", tt.solution, tt.players, got, tt.want)
+		}
+	}
+}
+
+// coopWorld starts a world where alice has the co-op quest 3, with quest 1
+// solved, and bob only has quest 1, unsolved.
+func coopWorld(t *testing.T) (alice, bob *Game) {
+	t.Helper()
+	cfg, _ := testConfig(t, "normal", 42)
+	w := NewWorld(cfg)
+	var err error
+	if alice, err = w.Join("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if bob, err = w.Join("bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	first := questByID(t, alice, 1)
+	first.Solved = true
+	alice.Player.Quests = []*Quest{first, questByID(t, alice, 3)}
+	bob.Player.Quests = []*Quest{questByID(t, bob, 1)}
+	return alice, bob
+}
+
+func TestCoopNeedsTheOwnerToStart(t *testing.T) {
+	_, bob := coopWorld(t)
+	out := run(bob, "start 3")
+	if bob.activeQuest != nil {
+		t.Fatal("bob started a co-op quest nobody who has it had started")
+	}
+	if !strings.Contains(out, "Nobody is working on") {
+		t.Errorf("bob was told %q", out)
+	}
+}
+
+func TestCoopHelperJoinsAndSolves(t *testing.T) {
+	alice, bob := coopWorld(t)
+
+	run(alice, "start 3")
+	if alice.activeQuest == nil {
+		t.Fatal("alice could not start the co-op quest")
+	}
+	// Bob has not solved quest 1, which quest 3 requires, but only alice
+	// has to meet its requirements.
+	run(bob, "start 3")
+	if bob.activeQuest == nil {
+		t.Fatal("bob could not join the quest alice started")
+	}
+
+	parts := coopParts(alice.activeQuest)
+	run(alice, strings.Join(parts[0], " "))
+	if questByID(t, alice, 3).Solved {
+		t.Fatal("the quest was solved with one part of two")
+	}
+	run(bob, strings.Join(parts[1], " "))
+
+	if !questByID(t, alice, 3).Solved || !questByID(t, bob, 3).Solved {
+		t.Fatal("the quest is not solved for both players")
+	}
+	if alice.Player.CoopCredit[3] != 1 || bob.Player.CoopCredit[3] != 2 {
+		t.Errorf("credits %v and %v, want parts 1 and 2", alice.Player.CoopCredit, bob.Player.CoopCredit)
+	}
+	if bob.hasQuest(questByID(t, bob, 3)) {
+		t.Error("helping added the quest to bob's own quests")
+	}
+}
+
+func TestCoopHelperMustBeThere(t *testing.T) {
+	alice, bob := coopWorld(t)
+	run(alice, "start 3")
+	run(bob, "go north", "start 3")
+	if bob.activeQuest != nil {
+		t.Fatal("bob joined from another room")
+	}
+}
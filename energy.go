@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Energy costs of actions. An answer costs attemptEnergyPerDifficulty
+// points per difficulty level of the quest, on top of the penalty of the
+// mode for a wrong answer.
+const (
+	maxEnergy                  = 100
+	moveEnergyCost             = 2
+	attemptEnergyPerDifficulty = 2
+)
+
+// Resting trades restEnergyPerMinute points of energy for every minute of
+// the remaining time.
+const (
+	restEnergyPerMinute = 5
+	defaultRest         = 5 * time.Minute
+	maxRest             = 30 * time.Minute
+)
+
+// attemptEnergy returns what one answer to quest costs; a skilled player
+// pays half.
+func (g *Game) attemptEnergy(quest *Quest) int {
+	if g.mode().UnlimitedEnergy {
+		return 0
+	}
+	cost := quest.Difficulty * attemptEnergyPerDifficulty
+	if g.skillAtLeast(quest.Category, perkEnergySkill) {
+		cost /= 2
+	}
+	return cost
+}
+
+// haveEnergy reports whether the player can afford an action costing cost
+// energy, and explains how to get more if not.
+func (g *Game) haveEnergy(cost int) bool {
+	if g.mode().UnlimitedEnergy || g.Player.Stats.Energy >= cost {
+		return true
+	}
+	g.message(EventWarning, fmt.Sprintf("🔋 You are too exhausted: that needs %d energy and you have %d.", cost, g.Player.Stats.Energy))
+	g.message(EventInfo, "Type 'rest' to recover energy for some of your time, or use an energy cell.")
+	return false
+}
+
+// restoreEnergy adds energy up to the maximum and returns how much was
+// actually gained.
+func (g *Game) restoreEnergy(amount int) int {
+	stats := g.Player.Stats
+	if stats.Energy+amount > maxEnergy {
+		amount = maxEnergy - stats.Energy
+	}
+	stats.Energy += amount
+	return amount
+}
+
+// Rest spends d of the remaining time to recover energy. Resting stops as
+// soon as energy is full, so no time is wasted.
+func (g *Game) Rest(d time.Duration) {
+	switch {
+	case g.mode().UnlimitedEnergy:
+		g.message(EventInfo, fmt.Sprintf("Energy never runs out in %s mode, no need to rest.", g.GameMode))
+		return
+	case d < time.Minute || d > maxRest:
+		g.message(EventError, fmt.Sprintf("You can rest between 1 and %d minutes.", int(maxRest.Minutes())))
+		return
+	case g.Player.Stats.Energy >= maxEnergy:
+		g.message(EventInfo, "You are fully rested already.")
+		return
+	}
+
+	minutes := int(d / time.Minute)
+	if needed := (maxEnergy - g.Player.Stats.Energy + restEnergyPerMinute - 1) / restEnergyPerMinute; minutes > needed {
+		minutes = needed
+	}
+	gained := g.restoreEnergy(minutes * restEnergyPerMinute)
+	g.chargeTime(time.Duration(minutes) * time.Minute)
+	g.announce(g.Player.CurrentRoom, fmt.Sprintf("%s sits down to rest.", g.Player.Name))
+	g.message(EventSuccess, fmt.Sprintf("😴 You rest for %d minutes: +%d energy (%d/%d).", minutes, gained, g.Player.Stats.Energy, maxEnergy))
+	g.pause(2 * time.Second)
+	g.Look()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAttemptEnergy(t *testing.T) {
+	tests := []struct {
+		mode       string
+		difficulty int
+		skill      int
+		want       int
+	}{
+		{"normal", 3, 0, 6},
+		{"normal", 3, perkEnergySkill - 1, 6},
+		{"normal", 3, perkEnergySkill, 3},
+		{"hardcore", 5, 0, 10},
+		{"tutorial", 5, 0, 0},
+	}
+	for _, tt := range tests {
+		g, _ := newTestGame(t, tt.mode, 7)
+		g.Player.Stats.Hacking = tt.skill
+		quest := &Quest{Category: HackerQuest, Difficulty: tt.difficulty}
+		if got := g.attemptEnergy(quest); got != tt.want {
+			t.Errorf("%s, difficulty %d, skill %d: %d energy, want %d", tt.mode, tt.difficulty, tt.skill, got, tt.want)
+		}
+	}
+}
+
+func TestMovingCostsEnergy(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	start := g.Player.CurrentRoom
+
+	g.Player.Stats.Energy = moveEnergyCost - 1
+	run(g, "go north")
+	if g.Player.CurrentRoom != start {
+		t.Fatal("walked away without the energy for it")
+	}
+
+	g.Player.Stats.Energy = 10
+	run(g, "go north")
+	if g.Player.CurrentRoom == start {
+		t.Fatal("could not walk north with enough energy")
+	}
+	if g.Player.Stats.Energy != 10-moveEnergyCost {
+		t.Errorf("energy %d after a move, want %d", g.Player.Stats.Energy, 10-moveEnergyCost)
+	}
+}
+
+func TestRest(t *testing.T) {
+	tests := []struct {
+		name    string
+		energy  int
+		command string
+		want    int           // energy afterwards
+		charged time.Duration // time spent resting
+	}{
+		{"default", 50, "rest", 50 + 5*restEnergyPerMinute, defaultRest},
+		{"stops when full", 88, "rest 10", maxEnergy, 3 * time.Minute},
+		{"already full", maxEnergy, "rest 10", maxEnergy, 0},
+		{"too long", 50, "rest 31", 50, 0},
+		{"too short", 50, "rest 0", 50, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := newTestGame(t, "normal", 7)
+			g.Player.Stats.Energy = tt.energy
+			left := g.Player.Stats.TimeLeft
+			run(g, tt.command)
+			if g.Player.Stats.Energy != tt.want {
+				t.Errorf("energy %d, want %d", g.Player.Stats.Energy, tt.want)
+			}
+			if spent := left - g.Player.Stats.TimeLeft; spent != tt.charged {
+				t.Errorf("rest took %v, want %v", spent, tt.charged)
+			}
+		})
+	}
+}
+
+func TestNoRestWithUnlimitedEnergy(t *testing.T) {
+	g, _ := newTestGame(t, "tutorial", 7)
+	left := g.Player.Stats.TimeLeft
+	run(g, "rest 10")
+	if g.Player.Stats.TimeLeft != left {
+		t.Errorf("resting in tutorial mode took %v", left-g.Player.Stats.TimeLeft)
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// escapeDef describes how to escape a level: the airlock room, the keys
+// that open its door and the closing puzzle solved inside.
+type escapeDef struct {
+	Room       string    `json:"room"`       // key of the airlock room
+	Rewards    []string  `json:"rewards"`    // reward items that count as keys
+	Categories []string  `json:"categories"` // a solved quest of one of these counts as a key
+	Needs      int       `json:"needs"`      // keys needed to open the door; all of them if zero
+	Puzzle     puzzleDef `json:"puzzle"`
+}
+
+// puzzleDef is the closing puzzle. Its clues are only shown once the player
+// has earned them.
+type puzzleDef struct {
+	Question string      `json:"question"`
+	Answer   string      `json:"answer"`
+	Answers  []string    `json:"answers"`
+	Match    AnswerMatch `json:"match"`
+	Clues    []clueDef   `json:"clues"`
+}
+
+// clueDef is shown while the player carries Item, or once they solved a
+// quest of Category.
+type clueDef struct {
+	Item     string `json:"item"`
+	Category string `json:"category"`
+	Text     string `json:"text"`
+}
+
+// validate checks the escape against the rooms of the level.
+func (e *escapeDef) validate(rooms map[string]int) []string {
+	var problems []string
+	if _, ok := rooms[e.Room]; !ok {
+		problems = append(problems, fmt.Sprintf("escape room %q does not exist", e.Room))
+	}
+	for _, key := range e.Categories {
+		if _, ok := categoryKeys[key]; !ok {
+			problems = append(problems, fmt.Sprintf("escape needs unknown category %q", key))
+		}
+	}
+	if keys := len(e.Rewards) + len(e.Categories); e.Needs < 0 || e.Needs > keys {
+		problems = append(problems, fmt.Sprintf("escape needs %d keys but lists %d", e.Needs, keys))
+	}
+	if strings.TrimSpace(e.Puzzle.Answer) == "" {
+		problems = append(problems, "escape puzzle has no answer")
+	} else if _, err := newAnswerChecker(e.Puzzle.Answer, e.Puzzle.Answers, e.Puzzle.Match); err != nil {
+		problems = append(problems, fmt.Sprintf("escape puzzle: %v", err))
+	}
+	for _, clue := range e.Puzzle.Clues {
+		if (clue.Item == "") == (clue.Category == "") {
+			problems = append(problems, fmt.Sprintf("escape clue %q must name either an item or a category", clue.Text))
+		} else if _, ok := categoryKeys[clue.Category]; clue.Category != "" && !ok {
+			problems = append(problems, fmt.Sprintf("escape clue %q names unknown category %q", clue.Text, clue.Category))
+		}
+	}
+	return problems
+}
+
+// escape returns how the level is escaped, or nil if solving every quest
+// wins the game. A game without the airlock room, such as one loaded from
+// a save that could not be given it, is won the old way too.
+func (g *Game) escape() *escapeDef {
+	if g.Config.Level == nil || g.Config.Level.Escape == nil {
+		return nil
+	}
+	if e := g.Config.Level.Escape; g.Rooms[e.Room] != nil {
+		return e
+	}
+	return nil
+}
+
+// solvedCategory reports whether the player solved a quest of category.
+func (g *Game) solvedCategory(category QuestCategory) bool {
+	for _, quest := range g.Player.Quests {
+		if quest.Category == category && quest.Solved {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeKey is one of the keys of the airlock door.
+type escapeKey struct {
+	Name string
+	Have bool
+}
+
+// escapeKeys lists the keys of the airlock door and how many of them open
+// it. A key the player's quests cannot give does not count, so every game
+// can be escaped; if they give none, the door needs no key and opens once
+// every quest is solved.
+func (g *Game) escapeKeys() ([]escapeKey, int) {
+	e := g.escape()
+	var keys []escapeKey
+	possible := 0
+	for _, name := range e.Rewards {
+		have := g.Player.hasItem(name)
+		for _, quest := range g.Player.Quests {
+			if have || strings.EqualFold(quest.Reward, name) {
+				possible++
+				break
+			}
+		}
+		keys = append(keys, escapeKey{Name: name, Have: have})
+	}
+	for _, key := range e.Categories {
+		category := categoryKeys[key]
+		for _, quest := range g.Player.Quests {
+			if quest.Category == category {
+				possible++
+				break
+			}
+		}
+		keys = append(keys, escapeKey{Name: "a solved quest: " + getCategoryName(category), Have: g.solvedCategory(category)})
+	}
+
+	needs := e.Needs
+	if needs == 0 {
+		needs = len(keys)
+	}
+	if needs > possible {
+		needs = possible
+	}
+	return keys, needs
+}
+
+// questsSolved reports whether the player solved every one of their
+// quests.
+func (g *Game) questsSolved() bool {
+	for _, quest := range g.Player.Quests {
+		if !quest.Solved {
+			return false
+		}
+	}
+	return true
+}
+
+// airlockOpen reports whether the player may enter room, explaining which
+// keys are missing if it is the airlock and it stays shut.
+func (g *Game) airlockOpen(room *Room) bool {
+	e := g.escape()
+	if e == nil || g.Rooms[e.Room] != room {
+		return true
+	}
+	keys, needs := g.escapeKeys()
+	have := 0
+	for _, key := range keys {
+		if key.Have {
+			have++
+		}
+	}
+	if needs == 0 {
+		if g.questsSolved() {
+			return true
+		}
+		g.message(EventWarning, fmt.Sprintf("🚪 The door of the %s stays shut: none of your quests gives one of its keys, so it only opens once you solved them all.", room.Name))
+		return false
+	}
+	if have >= needs {
+		return true
+	}
+
+	g.message(EventWarning, fmt.Sprintf("🚪 The door of the %s stays shut: it needs %d of its keys and you have %d.", room.Name, needs, have))
+	for _, key := range keys {
+		mark := "❌"
+		if key.Have {
+			mark = "✅"
+		}
+		g.message(EventText, fmt.Sprintf("  %s %s", mark, key.Name))
+	}
+	return false
+}
+
+// clues returns the clues of the closing puzzle the player has earned.
+func (g *Game) clues() []string {
+	var clues []string
+	for _, clue := range g.escape().Puzzle.Clues {
+		if (clue.Item != "" && g.Player.hasItem(clue.Item)) ||
+			(clue.Category != "" && g.solvedCategory(categoryKeys[clue.Category])) {
+			clues = append(clues, clue.Text)
+		}
+	}
+	return clues
+}
+
+// Escape shows the closing puzzle of the airlock, or tries code as its
+// answer.
+func (g *Game) Escape(code string) {
+	e := g.escape()
+	if e == nil {
+		g.message(EventInfo, "There is no airlock here: complete all your quests to escape.")
+		return
+	}
+	airlock := g.Rooms[e.Room]
+	if g.Player.CurrentRoom != airlock {
+		g.message(EventWarning, fmt.Sprintf("The escape console is in the %s.", airlock.Name))
+		return
+	}
+
+	if code == "" {
+		g.message(EventInfo, "🧩 "+e.Puzzle.Question)
+		clues := g.clues()
+		if len(clues) == 0 {
+			g.message(EventWarning, "You have no clues yet. Solve quests and look around the station.")
+		}
+		for _, clue := range clues {
+			g.message(EventText, "  🔎 "+clue)
+		}
+		g.message(EventInfo, "Type 'escape <code>' to enter the code.")
+		return
+	}
+
+	g.chargeTime(g.Config.Clock.AttemptCost)
+	checker, err := newAnswerChecker(e.Puzzle.Answer, e.Puzzle.Answers, e.Puzzle.Match)
+	if err != nil || !checker.Check(code) {
+		g.message(EventError, "ACCESS DENIED. The console buzzes angrily.")
+		g.Player.WrongAttempts++
+		g.spendEnergy(g.mode().WrongAnswerEnergy)
+		return
+	}
+
+	g.emit(Event{Kind: EventClear})
+	g.message(EventSuccess, "🚀 ACCESS GRANTED! The airlock cycles open and you drift out to the waiting shuttle.")
+	g.message(EventSuccess, "You have successfully escaped the Cosmic Cyberpunk Room!")
+	g.announce(airlock, fmt.Sprintf("%s has escaped the station!", g.Player.Name))
+	g.finish(OutcomeWon)
+}
+
+// endView sums up the game for the end screen.
+func (g *Game) endView(outcome Outcome) *EndView {
+	taken := g.budget() - g.Player.Stats.TimeLeft
+	if taken < 0 {
+		taken = 0
+	}
+	level, r := levelFor(g.Player.Stats.XP)
+	return &EndView{
+		Outcome:       outcome.String(),
+		Score:         g.score(outcome == OutcomeWon),
+		TimeTaken:     taken,
+		HintsUsed:     g.hintsUsed(),
+		WrongAttempts: g.Player.WrongAttempts,
+		EnergyLeft:    g.Player.Stats.Energy,
+		Completed:     g.Player.Completed,
+		Total:         len(g.Player.Quests),
+		Level:         level,
+		Rank:          r.Title,
+		Mode:          g.mode().Name,
+		Seed:          g.Seed,
+	}
+}
+
+// finish ends the game with outcome and shows the end screen.
+func (g *Game) finish(outcome Outcome) {
+	g.pause(3 * time.Second)
+	end := g.endView(outcome)
+	g.emit(Event{Kind: EventEnd, End: end})
+	g.recordRun(end)
+	g.outcome = outcome
+	if outcome == OutcomeLost {
+		g.removeSingleSave()
+	}
+}
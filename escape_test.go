@@ -0,0 +1,121 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// giveEscapeKeys hands the player two of the rewards that are keys of the
+// airlock door, which opens it.
+func giveEscapeKeys(g *Game) {
+	giveItem(g, "Квантовый ключ", false)
+	giveItem(g, "Генетический ключ", false)
+}
+
+func TestAirlockDoor(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	control, airlock := g.Rooms["cyber control room"], g.Rooms["airlock"]
+
+	out := run(g, "go south")
+	if g.Player.CurrentRoom != control {
+		t.Fatal("walked into the airlock without any keys")
+	}
+	if !strings.Contains(out, "stays shut") {
+		t.Errorf("the shut door was not explained: %q", out)
+	}
+
+	giveEscapeKeys(g)
+	run(g, "go south")
+	if g.Player.CurrentRoom != airlock {
+		t.Fatal("the airlock stayed shut with enough keys")
+	}
+}
+
+func TestEscapeKeysNeedOnlyWhatTheQuestsGive(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	g.Player.Quests = []*Quest{questByID(t, g, 1)} // gives the Кибер-ключ
+	keys, needs := g.escapeKeys()
+	if len(keys) != 5 {
+		t.Errorf("%d keys, want 5", len(keys))
+	}
+	if needs != 1 {
+		t.Errorf("needs %d keys with a single quest, want 1", needs)
+	}
+}
+
+func TestAirlockWithoutKeysOpensOnceQuestsAreSolved(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	quest := questByID(t, g, 21) // gives the Энерго-модуль, no key
+	g.Player.Quests = []*Quest{quest}
+	if _, needs := g.escapeKeys(); needs != 0 {
+		t.Fatalf("needs %d keys when the quests give none, want 0", needs)
+	}
+
+	if out := run(g, "go south"); g.Player.CurrentRoom == g.Rooms["airlock"] || !strings.Contains(out, "solved them all") {
+		t.Fatalf("the door opened, or did not say why not, with the quest unsolved: %q", out)
+	}
+	quest.Solved = true
+	run(g, "go south")
+	if g.Player.CurrentRoom != g.Rooms["airlock"] {
+		t.Error("the airlock stayed shut with every quest solved")
+	}
+}
+
+func TestEscapePuzzle(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	if out := run(g, "escape 4321"); !strings.Contains(out, "escape console is in the") {
+		t.Errorf("escape outside the airlock said %q", out)
+	}
+
+	giveEscapeKeys(g)
+	run(g, "go south")
+
+	resp := g.HandleProtocol(ProtocolCommand{Cmd: "escape"})
+	if !resp.OK || !strings.Contains(resp.Events[0].Text, "override code") {
+		t.Fatalf("escape without a code: %+v", resp)
+	}
+	energy := g.Player.Stats.Energy
+	resp = g.HandleProtocol(ProtocolCommand{Cmd: "escape", Answer: "1234"})
+	if resp.Outcome != OutcomeContinue.String() || g.Player.WrongAttempts != 1 {
+		t.Fatalf("wrong code: outcome %s, %d wrong attempts", resp.Outcome, g.Player.WrongAttempts)
+	}
+	if g.Player.Stats.Energy != energy-g.mode().WrongAnswerEnergy {
+		t.Errorf("energy %d after a wrong code, want %d", g.Player.Stats.Energy, energy-g.mode().WrongAnswerEnergy)
+	}
+
+	resp = g.HandleProtocol(ProtocolCommand{Cmd: "escape", Answer: "4321"})
+	if resp.Outcome != OutcomeWon.String() {
+		t.Fatalf("right code: outcome %s", resp.Outcome)
+	}
+	var end *EndView
+	for _, ev := range resp.Events {
+		if ev.Kind == EventEnd {
+			end = ev.End
+		}
+	}
+	if end == nil || end.WrongAttempts != 1 || end.Outcome != OutcomeWon.String() {
+		t.Errorf("end screen %+v, want a won game with one wrong attempt", end)
+	}
+}
+
+func TestGameWithoutAirlockIsWonByQuests(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	delete(g.Rooms, "airlock")
+	if g.escape() != nil {
+		t.Fatal("escape() names an airlock the game does not have")
+	}
+	if out := run(g, "escape"); !strings.Contains(out, "no airlock") {
+		t.Errorf("escape said %q, want that there is no airlock", out)
+	}
+
+	for _, quest := range g.Player.Quests {
+		quest.Solved = true
+	}
+	g.Player.Completed = len(g.Player.Quests) - 1
+	last := g.Player.Quests[len(g.Player.Quests)-1]
+	last.Solved = false
+	g.completeQuest(last, false)
+	if g.outcome != OutcomeWon {
+		t.Errorf("outcome %s, want won", g.outcome)
+	}
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// EventKind identifies what an Event describes.
+type EventKind string
+
+const (
+	EventClear       EventKind = "clear"       // start a fresh screen
+	EventBanner      EventKind = "banner"      // the title banner
+	EventText        EventKind = "text"        // plain text
+	EventInfo        EventKind = "info"        // informational message
+	EventSuccess     EventKind = "success"     // something went well
+	EventWarning     EventKind = "warning"     // something to watch out for
+	EventError       EventKind = "error"       // something failed
+	EventPause       EventKind = "pause"       // give the player Delay to read
+	EventRoom        EventKind = "room"        // the current room
+	EventInventory   EventKind = "inventory"   // the player's inventory
+	EventQuests      EventKind = "quests"      // the player's quest list
+	EventQuest       EventKind = "quest"       // a quest briefing, awaiting an answer
+	EventHints       EventKind = "hints"       // hints for a quest
+	EventStats       EventKind = "stats"       // detailed player stats
+	EventHelp        EventKind = "help"        // the list of commands
+	EventMap         EventKind = "map"         // every room and how they connect
+	EventEnd         EventKind = "end"         // the end screen of a finished game
+	EventLeaderboard EventKind = "leaderboard" // the best runs, filtered as described by Text
+)
+
+// Event is one piece of output produced by the engine. Only the fields that
+// matter for its Kind are set.
+type Event struct {
+	Kind     EventKind          `json:"kind"`
+	Text     string             `json:"text,omitempty"`
+	Delay    time.Duration      `json:"delay,omitempty"`
+	Room     *RoomView          `json:"room,omitempty"`
+	Items    []ItemView         `json:"items,omitempty"`
+	Quests   []QuestView        `json:"quests,omitempty"`
+	Quest    *QuestView         `json:"quest,omitempty"`
+	Stats    *StatsView         `json:"stats,omitempty"`
+	Commands []CommandHelp      `json:"commands,omitempty"`
+	Map      []MapRoomView      `json:"map,omitempty"`
+	End      *EndView           `json:"end,omitempty"`
+	Runs     []LeaderboardEntry `json:"runs,omitempty"`
+}
+
+// PromptMode tells the frontend what kind of input the engine expects next.
+type PromptMode int
+
+const (
+	PromptCommand  PromptMode = iota // a command
+	PromptAnswer                     // the answer to the started quest
+	PromptContinue                   // any key to leave the menu on screen
+)
+
+func (m PromptMode) String() string {
+	switch m {
+	case PromptAnswer:
+		return "answer"
+	case PromptContinue:
+		return "continue"
+	default:
+		return "command"
+	}
+}
+
+// MarshalText writes the mode by name in JSON output.
+func (m PromptMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// Prompt describes the input the engine is waiting for.
+type Prompt struct {
+	Mode          PromptMode    `json:"mode"`
+	QuestTimeLeft time.Duration `json:"quest_time_left,omitempty"` // time left to answer, for PromptAnswer; zero without a limit
+}
+
+// Outcome tells the frontend whether the game goes on.
+type Outcome int
+
+const (
+	OutcomeContinue Outcome = iota
+	OutcomeWon
+	OutcomeLost
+	OutcomeQuit
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeContinue:
+		return "continue"
+	case OutcomeWon:
+		return "won"
+	case OutcomeLost:
+		return "lost"
+	case OutcomeQuit:
+		return "quit"
+	default:
+		return "unknown"
+	}
+}
+
+// Result is everything a single command produced.
+type Result struct {
+	Events  []Event
+	Outcome Outcome
+}
+
+// ItemView describes an item.
+type ItemView struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ASCII       string `json:"ascii,omitempty"`
+	Energy      int    `json:"energy,omitempty"` // energy restored by using it
+	Consumable  bool   `json:"consumable,omitempty"`
+}
+
+// ExitView describes an exit of a room.
+type ExitView struct {
+	Direction string `json:"direction"`
+	Locked    bool   `json:"locked,omitempty"`
+}
+
+// MapRoomView describes a room on the map.
+type MapRoomView struct {
+	Name     string        `json:"name"`
+	Here     bool          `json:"here,omitempty"` // the player stands in it
+	Requires []string      `json:"requires,omitempty"`
+	Exits    []MapExitView `json:"exits"`
+}
+
+// MapExitView describes an exit on the map and where it leads.
+type MapExitView struct {
+	Direction string `json:"direction"`
+	To        string `json:"to"`
+	LockedBy  string `json:"locked_by,omitempty"`
+}
+
+// RoomView describes the room the player stands in.
+type RoomView struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	ASCII       string        `json:"ascii,omitempty"`
+	Items       []ItemView    `json:"items"`
+	Exits       []ExitView    `json:"exits"`
+	Stats       StatsView     `json:"stats"`
+	Warning     string        `json:"warning,omitempty"`
+	Players     []string      `json:"players,omitempty"` // other players here
+	Stations    []StationView `json:"stations,omitempty"`
+	Solved      bool          `json:"solved,omitempty"` // all quests here are done
+}
+
+// StationView describes an interactive object in a room and the player's
+// quests played at it.
+type StationView struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Quests      []int  `json:"quests,omitempty"`
+	Solved      bool   `json:"solved,omitempty"`
+}
+
+// StatsView describes the player's stats.
+type StatsView struct {
+	Hacking     int           `json:"hacking"`
+	Engineering int           `json:"engineering"`
+	Astronomy   int           `json:"astronomy"`
+	Biology     int           `json:"biology"`
+	Physics     int           `json:"physics"`
+	Energy      int           `json:"energy"`
+	TimeLeft    time.Duration `json:"time_left"`
+	Completed   int           `json:"completed"`
+	Total       int           `json:"total"`
+	Seed        int64         `json:"seed"`
+	Mode        string        `json:"mode"`
+	CoopSolved  int           `json:"coop_solved"`
+	XP          int           `json:"xp"`
+	Level       int           `json:"level"`
+	Rank        string        `json:"rank"`
+	NextLevelXP int           `json:"next_level_xp,omitempty"` // zero at the top rank
+	Perks       []string      `json:"perks,omitempty"`
+	Score       int           `json:"score"` // score so far, without the escape bonus
+}
+
+// QuestView describes a quest. Hints and Example are only filled in where
+// the player asked for them, and only with the hints revealed so far.
+type QuestView struct {
+	ID          int           `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Category    QuestCategory `json:"category"`
+	Difficulty  int           `json:"difficulty"`
+	TimeLimit   time.Duration `json:"time_limit"`
+	Reward      string        `json:"reward"`
+	Solved      bool          `json:"solved"`
+	Locked      []string      `json:"locked,omitempty"` // unmet requirements
+	ASCII       string        `json:"ascii,omitempty"`
+	HintCount   int           `json:"hint_count"`
+	HintsUsed   int           `json:"hints_used"`
+	EnergyCost  int           `json:"energy_cost"` // energy every answer costs
+	Hints       []string      `json:"hints,omitempty"`
+	Example     string        `json:"example,omitempty"`
+	Coop        *CoopView     `json:"coop,omitempty"`
+	Location    string        `json:"location,omitempty"` // where it is played; empty if anywhere
+}
+
+// CoopView describes how a co-op quest is split between players. Claimed
+// holds, for each part, the player who entered it or "".
+type CoopView struct {
+	Players   int           `json:"players"`
+	Window    time.Duration `json:"window"`
+	PartSizes []int         `json:"part_sizes"`
+	Claimed   []string      `json:"claimed,omitempty"`
+}
+
+// EndView sums up a finished game.
+type EndView struct {
+	Outcome       string        `json:"outcome"`
+	Score         int           `json:"score"`
+	TimeTaken     time.Duration `json:"time_taken"`
+	HintsUsed     int           `json:"hints_used"`
+	WrongAttempts int           `json:"wrong_attempts"`
+	EnergyLeft    int           `json:"energy_left"`
+	Completed     int           `json:"completed"`
+	Total         int           `json:"total"`
+	Level         int           `json:"level"`
+	Rank          string        `json:"rank"`
+	Mode          string        `json:"mode"`
+	Seed          int64         `json:"seed"`
+}
+
+// CommandHelp describes one command in the help screen.
+type CommandHelp struct {
+	Usage string `json:"usage"`
+	Text  string `json:"text"`
+}
+
+func itemView(item *Item) ItemView {
+	return ItemView{Name: item.Name, Description: item.Description, ASCII: item.ASCII, Energy: item.energy(), Consumable: item.Consumable}
+}
+
+func itemViews(items []*Item) []ItemView {
+	views := make([]ItemView, 0, len(items))
+	for _, item := range items {
+		views = append(views, itemView(item))
+	}
+	return views
+}
+
+func questView(quest *Quest) QuestView {
+	return QuestView{
+		ID:          quest.ID,
+		Name:        quest.Name,
+		Description: quest.Description,
+		Category:    quest.Category,
+		Difficulty:  quest.Difficulty,
+		TimeLimit:   quest.TimeLimit,
+		Reward:      quest.Reward,
+		Solved:      quest.Solved,
+		ASCII:       quest.ASCII,
+		HintCount:   len(quest.Hints),
+	}
+}
+
+func (g *Game) statsView() StatsView {
+	stats := g.Player.Stats
+	level, r := levelFor(stats.XP)
+	next := 0
+	if level < len(ranks) {
+		next = ranks[level].XP
+	}
+	return StatsView{
+		XP:          stats.XP,
+		Level:       level,
+		Rank:        r.Title,
+		NextLevelXP: next,
+		Perks:       g.perks(),
+		Score:       g.score(false),
+		Hacking:     stats.Hacking,
+		Engineering: stats.Engineering,
+		Astronomy:   stats.Astronomy,
+		Biology:     stats.Biology,
+		Physics:     stats.Physics,
+		Energy:      stats.Energy,
+		TimeLeft:    stats.TimeLeft,
+		Completed:   g.Player.Completed,
+		Total:       len(g.Player.Quests),
+		Seed:        g.Seed,
+		Mode:        g.mode().Name,
+		CoopSolved:  len(g.Player.CoopCredit),
+	}
+}
+
+// questViews describes the player's quests with their progress.
+func (g *Game) questViews() []QuestView {
+	views := make([]QuestView, 0, len(g.Player.Quests))
+	for _, quest := range g.Player.Quests {
+		view := questView(quest)
+		view.HintsUsed = g.Player.HintsUsed[quest.ID]
+		view.EnergyCost = g.attemptEnergy(quest)
+		view.TimeLimit = g.timeLimit(quest)
+		view.Location = g.locationName(quest)
+		if quest.Coop != nil && g.world != nil {
+			view.Coop = coopView(quest)
+		}
+		if !quest.Solved {
+			view.Locked = g.missingRequirements(quest)
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+func (g *Game) roomView() *RoomView {
+	room := g.Player.CurrentRoom
+
+	exits := make([]ExitView, 0, len(room.Exits))
+	for direction := range room.Exits {
+		_, locked := room.Locks[direction]
+		exits = append(exits, ExitView{Direction: direction, Locked: locked})
+	}
+	sort.Slice(exits, func(i, j int) bool { return exits[i].Direction < exits[j].Direction })
+
+	return &RoomView{
+		Name:        room.Name,
+		Description: room.Description,
+		ASCII:       room.ASCII,
+		Items:       itemViews(room.Items),
+		Exits:       exits,
+		Stats:       g.statsView(),
+		Warning:     g.timeWarning(),
+		Players:     g.othersHere(),
+		Stations:    g.stationViews(room),
+		Solved:      room.Solved,
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Puzzle is one generated instance of a quest.
+type Puzzle struct {
+	Solution string
+	Answers  []string
+	Match    AnswerMatch
+	ASCII    []string
+	Hints    []string
+	Example  string
+}
+
+// Generator creates a puzzle from a random source. The same source state
+// must always produce the same puzzle.
+type Generator func(r *rand.Rand) Puzzle
+
+// generators are the generators a quest can name in its "generator" field.
+var generators = map[string]Generator{
+	"binary-word":    binaryWordPuzzle,
+	"sequence":       sequencePuzzle,
+	"dna-repeat":     dnaRepeatPuzzle,
+	"terminal-order": terminalOrderPuzzle,
+}
+
+// Generate returns a copy of the quest ready to be played. Quests with a
+// generator get their solution, panel, hints and example from it, seeded
+// with seed; static quests are copied as they are.
+func (q *Quest) Generate(seed int64) *Quest {
+	quest := *q
+	generate, ok := generators[q.Generator]
+	if !ok {
+		return &quest
+	}
+
+	p := generate(rand.New(rand.NewSource(seed)))
+	quest.Seed = seed
+	quest.Solution = p.Solution
+	quest.Answers = p.Answers
+	quest.Match = p.Match
+	quest.ASCII = asciiArt(p.ASCII)
+	quest.Hints = p.Hints
+	quest.Example = p.Example
+	// Generators only use known normalisers, so this cannot fail; if it
+	// did, checkAnswer would fall back to an exact comparison.
+	quest.Checker, _ = newAnswerChecker(p.Solution, p.Answers, p.Match)
+	return &quest
+}
+
+// displayWidth estimates how many terminal columns s takes: emoji are two
+// columns wide and variation selectors take none.
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case r == '\uFE0F':
+		case r >= 0x1F300:
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+// panel draws lines inside the frame used by the quest screens.
+func panel(title string, lines ...string) []string {
+	const width = 30
+	pad := func(s string) string {
+		if n := width - displayWidth(s); n > 0 {
+			return s + strings.Repeat(" ", n)
+		}
+		return s
+	}
+	out := []string{"    ╔" + strings.Repeat("═", width+2) + "╗", "    ║ " + pad(title) + " ║"}
+	for _, line := range lines {
+		out = append(out, "    ║ "+pad(line)+" ║")
+	}
+	return append(out, "    ╚"+strings.Repeat("═", width+2)+"╝")
+}
+
+func binaryByte(c byte) string {
+	return fmt.Sprintf("%08b", c)
+}
+
+var binaryWords = []string{"HACK", "CODE", "BYTE", "ROOT", "NODE", "GRID", "NOVA", "STAR", "ECHO", "CYBER", "ORBIT", "QUBIT", "LASER", "PIXEL"}
+
+// binaryWordPuzzle hides a random word in ASCII bytes.
+func binaryWordPuzzle(r *rand.Rand) Puzzle {
+	word := binaryWords[r.Intn(len(binaryWords))]
+
+	bits := make([]string, len(word))
+	for i := range word {
+		bits[i] = binaryByte(word[i])
+	}
+	var lines []string
+	for i := 0; i < len(bits); i += 3 {
+		end := i + 3
+		if end > len(bits) {
+			end = len(bits)
+		}
+		lines = append(lines, strings.Join(bits[i:end], " "))
+	}
+
+	return Puzzle{
+		Solution: word,
+		Answers:  []string{strings.Join(bits, " ")},
+		Match:    AnswerMatch{Normalize: []string{"space"}},
+		ASCII:    panel("🔮 HOLOGRAM INTERFACE 🔮", lines...),
+		Hints: []string{
+			"💡 Подсказка 1: Это двоичный код. Каждая группа из 8 цифр представляет одну букву.",
+			fmt.Sprintf("💡 Подсказка 2: Переведите группы в символы ASCII. %s = %c, %s = %c", bits[0], word[0], bits[1], word[1]),
+			fmt.Sprintf("💡 Подсказка 3: Слово '%s' на английском языке.", word),
+		},
+		Example: "Пример: 01001000 = H, 01100001 = a → 'Ha'",
+	}
+}
+
+// sequencePuzzle asks for the next term of an arithmetic, geometric or
+// Fibonacci-like sequence.
+func sequencePuzzle(r *rand.Rand) Puzzle {
+	const shown = 6
+	terms := make([]int, shown+1)
+	var rule, kind string
+
+	switch r.Intn(3) {
+	case 0:
+		start, step := r.Intn(20)+1, r.Intn(9)+2
+		for i := range terms {
+			terms[i] = start + i*step
+		}
+		kind, rule = "арифметическая прогрессия", fmt.Sprintf("каждое число больше предыдущего на %d", step)
+	case 1:
+		start, ratio := r.Intn(4)+1, r.Intn(2)+2
+		terms[0] = start
+		for i := 1; i < len(terms); i++ {
+			terms[i] = terms[i-1] * ratio
+		}
+		kind, rule = "геометрическая прогрессия", fmt.Sprintf("каждое число в %d раза больше предыдущего", ratio)
+	default:
+		terms[0], terms[1] = r.Intn(5)+1, r.Intn(5)+1
+		for i := 2; i < len(terms); i++ {
+			terms[i] = terms[i-1] + terms[i-2]
+		}
+		kind, rule = "последовательность Фибоначчи", "каждое число равно сумме двух предыдущих"
+	}
+
+	cells := make([]string, shown)
+	for i, term := range terms[:shown] {
+		cells[i] = "[" + strconv.Itoa(term) + "]"
+	}
+	next := strconv.Itoa(terms[shown])
+
+	return Puzzle{
+		Solution: next,
+		ASCII:    panel("🧠 NEURO INTERFACE 🧠", strings.Join(cells[:3], " "), strings.Join(cells[3:], " "), "Find the next number..."),
+		Hints: []string{
+			fmt.Sprintf("💡 Подсказка 1: Это %s.", kind),
+			fmt.Sprintf("💡 Подсказка 2: Правило: %s.", rule),
+			fmt.Sprintf("💡 Подсказка 3: Следующее число — %s.", next),
+		},
+		Example: "Пример: 3, 6, 9, 12 → следующее 15",
+	}
+}
+
+const dnaBases = "ACGT"
+
+// dnaRepeatPuzzle shows a repeating DNA sequence with damaged bases the
+// player has to restore.
+func dnaRepeatPuzzle(r *rand.Rand) Puzzle {
+	unit := make([]byte, r.Intn(3)+3)
+	for i := range unit {
+		unit[i] = dnaBases[r.Intn(len(dnaBases))]
+	}
+	sequence := strings.Repeat(string(unit), 12/len(unit)+1)[:12]
+
+	// Damage a different base in each copy of the unit so every base
+	// survives somewhere and the sequence can always be restored
+	damaged := []byte(sequence)
+	offset := r.Intn(len(unit))
+	for i := 0; i < len(damaged); i += len(unit) {
+		if j := i + (offset+i/len(unit))%len(unit); j < len(damaged) {
+			damaged[j] = '?'
+		}
+	}
+
+	return Puzzle{
+		Solution: sequence,
+		Match:    AnswerMatch{Normalize: []string{"space"}},
+		ASCII:    panel("🧬 GENETIC LOCK 🧬", string(damaged), "Restore the damaged bases"),
+		Hints: []string{
+			"💡 Подсказка 1: Последовательность состоит из повторяющегося фрагмента.",
+			fmt.Sprintf("💡 Подсказка 2: Длина фрагмента — %d оснований.", len(unit)),
+			fmt.Sprintf("💡 Подсказка 3: Фрагмент — %s, полная последовательность — %s.", unit, sequence),
+		},
+		Example: "Пример: ATG?TGA?G → ATGATGATG",
+	}
+}
+
+// terminalOrderPuzzle lists terminal activations out of order; the player
+// has to sort them by time.
+func terminalOrderPuzzle(r *rand.Rand) Puzzle {
+	const steps = 5
+	terminals := r.Intn(2) + 3
+
+	order := make([]int, steps)
+	for i := range order {
+		order[i] = r.Intn(terminals) + 1
+		for i > 0 && order[i] == order[i-1] {
+			order[i] = r.Intn(terminals) + 1
+		}
+	}
+
+	type pulse struct{ terminal, at int }
+	pulses := make([]pulse, steps)
+	at := 0
+	for i, terminal := range order {
+		at += r.Intn(5) + 1
+		pulses[i] = pulse{terminal, at}
+	}
+	shuffled := append([]pulse(nil), pulses...)
+	r.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	// Keep the log from accidentally listing the answer in order
+	if sort.SliceIsSorted(shuffled, func(i, j int) bool { return shuffled[i].at < shuffled[j].at }) {
+		shuffled[0], shuffled[steps-1] = shuffled[steps-1], shuffled[0]
+	}
+
+	log := make([]string, steps)
+	for i, p := range shuffled {
+		log[i] = fmt.Sprintf("T%d pulse @ 00:%02d", p.terminal, p.at)
+	}
+
+	digits := make([]string, steps)
+	for i, terminal := range order {
+		digits[i] = strconv.Itoa(terminal)
+	}
+	solution := strings.Join(digits, "-")
+
+	return Puzzle{
+		Solution: solution,
+		Match:    AnswerMatch{Regex: strings.Join(digits, `\D*`)},
+		ASCII:    panel("🔐 QUANTUM TERMINALS 🔐", log...),
+		Hints: []string{
+			"💡 Подсказка 1: Журнал показывает импульсы терминалов вперемешку.",
+			"💡 Подсказка 2: Отсортируйте импульсы по времени и выпишите номера терминалов.",
+			fmt.Sprintf("💡 Подсказка 3: Порядок активации: %s.", solution),
+		},
+		Example: "Пример: T2 @ 00:05, T1 @ 00:02 → 1-2",
+	}
+}
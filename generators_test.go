@@ -0,0 +1,118 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGeneratorsAreDeterministic(t *testing.T) {
+	for name, generate := range generators {
+		for seed := int64(0); seed < 20; seed++ {
+			a := generate(rand.New(rand.NewSource(seed)))
+			b := generate(rand.New(rand.NewSource(seed)))
+			if !reflect.DeepEqual(a, b) {
+				t.Errorf("%s: seed %d gave two different puzzles", name, seed)
+			}
+		}
+	}
+}
+
+func TestGeneratedPuzzlesAreSolvable(t *testing.T) {
+	for name, generate := range generators {
+		for seed := int64(0); seed < 200; seed++ {
+			p := generate(rand.New(rand.NewSource(seed)))
+			checker, err := newAnswerChecker(p.Solution, p.Answers, p.Match)
+			if err != nil {
+				t.Fatalf("%s, seed %d: %v", name, seed, err)
+			}
+			for _, answer := range append([]string{p.Solution}, p.Answers...) {
+				if !checker.Check(answer) {
+					t.Errorf("%s, seed %d: answer %q is rejected", name, seed, answer)
+				}
+			}
+			if len(p.Hints) != 3 || !strings.Contains(p.Hints[2], p.Solution) {
+				t.Errorf("%s, seed %d: the last hint of %q does not give %q away", name, seed, p.Hints, p.Solution)
+			}
+			for _, line := range p.ASCII {
+				if displayWidth(line) != displayWidth(p.ASCII[0]) {
+					t.Errorf("%s, seed %d: panel line %q is out of line", name, seed, line)
+				}
+			}
+		}
+	}
+}
+
+// panelText returns the text of the panel lines below the title.
+func panelText(p Puzzle) []string {
+	var lines []string
+	for _, line := range p.ASCII[2 : len(p.ASCII)-1] {
+		lines = append(lines, strings.TrimSpace(strings.Trim(strings.TrimSpace(line), "║")))
+	}
+	return lines
+}
+
+func TestBinaryWordPuzzle(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		p := binaryWordPuzzle(rand.New(rand.NewSource(seed)))
+		var word []byte
+		for _, bits := range strings.Fields(strings.Join(panelText(p), " ")) {
+			c, err := strconv.ParseUint(bits, 2, 8)
+			if err != nil {
+				t.Fatalf("seed %d: %q is not a byte", seed, bits)
+			}
+			word = append(word, byte(c))
+		}
+		if string(word) != p.Solution {
+			t.Errorf("seed %d: the panel spells %q, want %q", seed, word, p.Solution)
+		}
+	}
+}
+
+func TestDNARepeatPuzzle(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		p := dnaRepeatPuzzle(rand.New(rand.NewSource(seed)))
+		damaged := panelText(p)[0]
+		if len(damaged) != len(p.Solution) || !strings.Contains(damaged, "?") {
+			t.Fatalf("seed %d: damaged %q, solution %q", seed, damaged, p.Solution)
+		}
+		for i := range damaged {
+			if damaged[i] != '?' && damaged[i] != p.Solution[i] {
+				t.Errorf("seed %d: %q does not match %q", seed, damaged, p.Solution)
+			}
+		}
+	}
+}
+
+func TestTerminalOrderPuzzle(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		p := terminalOrderPuzzle(rand.New(rand.NewSource(seed)))
+		log := panelText(p)
+		sorted := append([]string(nil), log...)
+		// Pulses are logged as "T<terminal> pulse @ 00:<second>"
+		at := func(line string) string { return line[strings.LastIndex(line, ":")+1:] }
+		sort.Slice(sorted, func(i, j int) bool { return at(sorted[i]) < at(sorted[j]) })
+		if reflect.DeepEqual(sorted, log) {
+			t.Errorf("seed %d: the log is already in order", seed)
+		}
+
+		var digits []string
+		for _, line := range sorted {
+			digits = append(digits, line[1:2])
+		}
+		if got := strings.Join(digits, "-"); got != p.Solution {
+			t.Errorf("seed %d: the log sorts to %s, want %s", seed, got, p.Solution)
+		}
+	}
+}
+
+func TestGenerateKeepsStaticQuests(t *testing.T) {
+	quest := &Quest{ID: 1, Solution: "42"}
+	generated := quest.Generate(7)
+	if generated == quest || !reflect.DeepEqual(generated, quest) {
+		t.Errorf("Generate(7) = %+v, want a copy of %+v", generated, quest)
+	}
+}
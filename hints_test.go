@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRevealHint(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   string
+		skill  int
+		energy []int // energy after each of the first two hints
+	}{
+		{"normal", "normal", 0, []int{100 - hintEnergyCost, 100 - 2*hintEnergyCost}},
+		{"first hint free with skill", "normal", perkHintSkill, []int{100, 100 - hintEnergyCost}},
+		{"tutorial", "tutorial", 0, []int{100, 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := newTestGame(t, tt.mode, 7)
+			quest := hintedQuest(t, g)
+			*g.skill(quest.Category) = tt.skill
+			id := strconv.Itoa(quest.ID)
+
+			for i, energy := range tt.energy {
+				run(g, "hint "+id)
+				if g.Player.HintsUsed[quest.ID] != i+1 {
+					t.Fatalf("%d hints revealed, want %d", g.Player.HintsUsed[quest.ID], i+1)
+				}
+				if g.Player.Stats.Energy != energy {
+					t.Errorf("energy %d after hint %d, want %d", g.Player.Stats.Energy, i+1, energy)
+				}
+			}
+		})
+	}
+}
+
+func TestLastHintNeedsConfirmation(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	quest := hintedQuest(t, g)
+	id := strconv.Itoa(quest.ID)
+	last := len(quest.Hints) - 1
+	g.Player.HintsUsed[quest.ID] = last
+
+	if out := run(g, "hint "+id); !strings.Contains(out, "last hint reveals the answer") {
+		t.Errorf("the last hint was not held back: %q", out)
+	}
+	if g.Player.HintsUsed[quest.ID] != last {
+		t.Fatal("the last hint was revealed without confirmation")
+	}
+	run(g, "hint "+id+" reveal")
+	if g.Player.HintsUsed[quest.ID] != len(quest.Hints) {
+		t.Fatal("the last hint was not revealed when confirmed")
+	}
+	if out := run(g, "hint "+id+" reveal"); !strings.Contains(out, "revealed every hint") {
+		t.Errorf("revealing past the last hint said %q", out)
+	}
+}
+
+func TestHintsRefused(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  string
+		setup func(g *Game, quest *Quest)
+		want  string
+	}{
+		{"hardcore", "hardcore", func(*Game, *Quest) {}, "no hints in hardcore mode"},
+		{"solved quest", "normal", func(_ *Game, q *Quest) { q.Solved = true }, "already completed"},
+		{"no hints", "normal", func(_ *Game, q *Quest) { q.Hints = nil }, "has no hints"},
+		{"unknown quest", "normal", func(g *Game, _ *Quest) { g.Player.Quests = nil }, "Quest not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := newTestGame(t, tt.mode, 7)
+			quest := g.Player.Quests[0]
+			tt.setup(g, quest)
+			out := run(g, "hint "+strconv.Itoa(quest.ID))
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("hint said %q, want %q", out, tt.want)
+			}
+			if g.Player.HintsUsed[quest.ID] != 0 || g.Player.Stats.Energy != 100 {
+				t.Error("a refused hint was revealed or paid for")
+			}
+		})
+	}
+}
+
+func TestHintNeedsEnergy(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	quest := hintedQuest(t, g)
+	g.Player.Stats.Energy = hintEnergyCost - 1
+
+	if out := run(g, "hint "+strconv.Itoa(quest.ID)); !strings.Contains(out, "too exhausted") {
+		t.Errorf("a hint without the energy for it said %q", out)
+	}
+	if g.Player.HintsUsed[quest.ID] != 0 || g.Player.Stats.Energy != hintEnergyCost-1 {
+		t.Error("a hint was revealed without the energy for it")
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Item effect kinds.
+const (
+	EffectUnlock = "unlock" // opens the exits of the room locked by the item
+	EffectEnergy = "energy" // restores Amount energy
+	EffectReveal = "reveal" // uncovers the hidden item named Target in the room
+	EffectHint   = "hint"   // reveals the next hint of a quest for free
+	EffectMap    = "map"    // shows every room and how they connect
+)
+
+// ItemEffect is something that happens when an item is used.
+type ItemEffect struct {
+	Kind   string `json:"kind"`
+	Target string `json:"target,omitempty"` // unlock: only this direction; reveal: the hidden item
+	Amount int    `json:"amount,omitempty"` // energy: points restored
+}
+
+// validateEffect checks an effect declared in a data file.
+func validateEffect(e ItemEffect) error {
+	switch e.Kind {
+	case EffectUnlock, EffectHint, EffectMap:
+	case EffectEnergy:
+		if e.Amount <= 0 || e.Amount > maxEnergy {
+			return fmt.Errorf("energy effect restores %d energy, must be between 1 and %d", e.Amount, maxEnergy)
+		}
+	case EffectReveal:
+		if strings.TrimSpace(e.Target) == "" {
+			return fmt.Errorf("reveal effect needs a target item")
+		}
+	default:
+		return fmt.Errorf("unknown effect %q: use %s, %s, %s, %s or %s", e.Kind, EffectUnlock, EffectEnergy, EffectReveal, EffectHint, EffectMap)
+	}
+	return nil
+}
+
+// energy returns how much energy using the item restores.
+func (item *Item) energy() int {
+	total := 0
+	for _, e := range item.Effects {
+		if e.Kind == EffectEnergy {
+			total += e.Amount
+		}
+	}
+	return total
+}
+
+func (item *Item) hasEffect(kind string) bool {
+	for _, e := range item.Effects {
+		if e.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// applyEffect carries out one effect of item on target, which may be
+// empty. It reports whether the effect did anything.
+func (g *Game) applyEffect(item *Item, e ItemEffect, target string) bool {
+	switch e.Kind {
+	case EffectUnlock:
+		if e.Target != "" && target == "" {
+			target = e.Target
+		}
+		return g.unlockWith(item, target)
+	case EffectEnergy:
+		return g.drain(item, e.Amount)
+	case EffectReveal:
+		return g.reveal(item, e.Target)
+	case EffectHint:
+		return g.grantHint(item, target)
+	case EffectMap:
+		g.message(EventSuccess, fmt.Sprintf("🗺️ The %s projects a map of the station.", item.Name))
+		g.emit(Event{Kind: EventMap, Map: g.mapView()})
+		return true
+	}
+	return false
+}
+
+// unlockWith opens the exits of the current room that item fits, or only
+// the one in direction if given.
+func (g *Game) unlockWith(item *Item, direction string) bool {
+	room := g.Player.CurrentRoom
+	unlocked := false
+	for dir, lock := range room.Locks {
+		if !strings.EqualFold(lock, item.Name) || (direction != "" && dir != direction) {
+			continue
+		}
+		delete(room.Locks, dir)
+		// The same door seen from the other side opens too
+		target := room.Exits[dir]
+		for back, backLock := range target.Locks {
+			if target.Exits[back] == room && backLock == lock {
+				delete(target.Locks, back)
+			}
+		}
+		g.message(EventSuccess, fmt.Sprintf("The %s unlocks the way %s!", item.Name, dir))
+		g.announce(room, fmt.Sprintf("%s unlocks the way %s.", g.Player.Name, dir))
+		unlocked = true
+	}
+	return unlocked
+}
+
+// drain restores energy from a consumable.
+func (g *Game) drain(item *Item, amount int) bool {
+	if g.mode().UnlimitedEnergy {
+		g.message(EventInfo, fmt.Sprintf("Energy never runs out in %s mode; keep the %s.", g.GameMode, item.Name))
+		return false
+	}
+	if g.Player.Stats.Energy >= maxEnergy {
+		g.message(EventInfo, fmt.Sprintf("Your energy is full; better keep the %s for later.", item.Name))
+		return false
+	}
+	gained := g.restoreEnergy(amount)
+	g.message(EventSuccess, fmt.Sprintf("🔋 You drain the %s: +%d energy (%d/%d).", item.Name, gained, g.Player.Stats.Energy, maxEnergy))
+	return true
+}
+
+// reveal uncovers a hidden item of the current room.
+func (g *Game) reveal(item *Item, name string) bool {
+	room := g.Player.CurrentRoom
+	for i, hidden := range room.Hidden {
+		if strings.EqualFold(hidden.Name, name) {
+			room.Hidden = append(room.Hidden[:i], room.Hidden[i+1:]...)
+			room.Items = append(room.Items, hidden)
+			g.message(EventSuccess, fmt.Sprintf("🔦 The %s reveals a %s!", item.Name, hidden.Name))
+			g.announce(room, fmt.Sprintf("%s finds a %s.", g.Player.Name, hidden.Name))
+			return true
+		}
+	}
+	return false
+}
+
+// grantHint reveals the next hint of the quest with ID target without any
+// energy cost. The hint still counts against the quest's experience, and
+// the last hint, which gives the answer away, is left to the hint command.
+func (g *Game) grantHint(item *Item, target string) bool {
+	if target == "" {
+		g.message(EventInfo, fmt.Sprintf("Use the %s on which quest? Type 'use %s on <quest_id>'.", item.Name, item.Name))
+		return false
+	}
+	questID, err := strconv.Atoi(target)
+	if err != nil {
+		g.message(EventError, "Invalid quest ID. Use a number.")
+		return false
+	}
+
+	quest := g.hintQuest(questID, false)
+	if quest == nil {
+		return false
+	}
+	g.Player.HintsUsed[quest.ID]++
+	g.emit(Event{Kind: EventClear})
+	g.message(EventSuccess, fmt.Sprintf("💾 The %s reveals a hint for %s.", item.Name, quest.Name))
+	// The hint came from the item, so there is no paid hint to offer
+	g.showHints(quest, false)
+	return true
+}
+
+// rewardItem creates the item quest awards, as the level defines it.
+func (g *Game) rewardItem(quest *Quest) *Item {
+	item := &Item{
+		Name:        quest.Reward,
+		Description: fmt.Sprintf("Reward for completing: %s", quest.Name),
+		Usable:      true,
+		ASCII:       fmt.Sprintf("    🎁 %s", quest.Reward),
+	}
+	if def, ok := g.Config.Level.reward(quest.Reward); ok {
+		item = def.build()
+		if item.ASCII == "" {
+			item.ASCII = fmt.Sprintf("    🎁 %s", quest.Reward)
+		}
+	}
+	item.QuestID = quest.ID
+	return item
+}
+
+// canEnter reports whether the player carries what room requires, and
+// explains what is missing if not.
+func (g *Game) canEnter(room *Room) bool {
+	if len(room.Requires) == 0 {
+		return true
+	}
+	for _, name := range room.Requires {
+		if g.Player.hasItem(name) {
+			return true
+		}
+	}
+	g.message(EventWarning, fmt.Sprintf("You can't enter the %s without the %s.", room.Name, strings.Join(room.Requires, " or the ")))
+	return false
+}
+
+// mapView describes every room of the world, sorted by name.
+func (g *Game) mapView() []MapRoomView {
+	views := make([]MapRoomView, 0, len(g.Rooms))
+	for _, room := range g.Rooms {
+		view := MapRoomView{Name: room.Name, Here: room == g.Player.CurrentRoom, Requires: room.Requires}
+		for direction, to := range room.Exits {
+			view.Exits = append(view.Exits, MapExitView{Direction: direction, To: to.Name, LockedBy: room.Locks[direction]})
+		}
+		sort.Slice(view.Exits, func(i, j int) bool { return view.Exits[i].Direction < view.Exits[j].Direction })
+		views = append(views, view)
+	}
+	sort.Slice(views, func(i, j int) bool { return views[i].Name < views[j].Name })
+	return views
+}
+
+// removeItem takes item out of the inventory.
+func (g *Game) removeItem(item *Item) {
+	for i, invItem := range g.Player.Inventory {
+		if invItem == item {
+			g.Player.Inventory = append(g.Player.Inventory[:i], g.Player.Inventory[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// giveItem puts a new item with effects into the player's inventory.
+func giveItem(g *Game, name string, consumable bool, effects ...ItemEffect) {
+	g.Player.Inventory = append(g.Player.Inventory, &Item{Name: name, Usable: true, Consumable: consumable, Effects: effects})
+}
+
+// hintedQuest returns a quest of the player with at least two hints.
+func hintedQuest(t *testing.T, g *Game) *Quest {
+	t.Helper()
+	for _, quest := range g.Player.Quests {
+		if len(quest.Hints) >= 2 {
+			return quest
+		}
+	}
+	t.Fatal("no quest of the player has two hints")
+	return nil
+}
+
+func TestHintEffect(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		used    func(quest *Quest) int // hints revealed before the item is used
+		reveals bool                   // the item reveals a hint
+		reply   string
+	}{
+		{"reveals the next hint", "normal", func(*Quest) int { return 0 }, true, "reveals a hint"},
+		{"keeps the answer back", "normal", func(q *Quest) int { return len(q.Hints) - 1 }, false, "last hint reveals the answer"},
+		{"has no hints in hardcore", "hardcore", func(*Quest) int { return 0 }, false, "no hints in hardcore"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := newTestGame(t, tt.mode, 7)
+			quest := hintedQuest(t, g)
+			used := tt.used(quest)
+			g.Player.HintsUsed[quest.ID] = used
+			giveItem(g, "data chip", true, ItemEffect{Kind: EffectHint})
+			energy := g.Player.Stats.Energy
+
+			out := run(g, "use data chip on "+strconv.Itoa(quest.ID))
+			want := used
+			if tt.reveals {
+				want++
+			}
+			if got := g.Player.HintsUsed[quest.ID]; got != want {
+				t.Errorf("%d hints revealed, want %d", got, want)
+			}
+			// A chip that revealed nothing is not used up
+			if g.Player.hasItem("data chip") == tt.reveals {
+				t.Errorf("data chip used up = %v, want %v", !tt.reveals, tt.reveals)
+			}
+			if g.Player.Stats.Energy != energy {
+				t.Errorf("energy %d, want %d: the chip is free", g.Player.Stats.Energy, energy)
+			}
+			if !strings.Contains(out, tt.reply) {
+				t.Errorf("output %q does not mention %q", out, tt.reply)
+			}
+		})
+	}
+}
+
+func TestHintItemRepliesBeforeTheHints(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	quest := hintedQuest(t, g)
+	giveItem(g, "data chip", true, ItemEffect{Kind: EffectHint})
+
+	reply, hints := -1, -1
+	for i, ev := range g.ProcessCommand("use data chip on " + strconv.Itoa(quest.ID)).Events {
+		switch {
+		case ev.Kind == EventSuccess && strings.Contains(ev.Text, "reveals a hint"):
+			reply = i
+		case ev.Kind == EventHints:
+			hints = i
+		case strings.Contains(ev.Text, "Type 'hint"):
+			// The chip paid for the hint, so none is offered for energy
+			t.Errorf("the chip offers a paid hint: %q", ev.Text)
+		}
+	}
+	if reply < 0 || hints < reply {
+		t.Errorf("reply at event %d and hints at %d, want the reply first", reply, hints)
+	}
+}
+
+func TestEnergyEffect(t *testing.T) {
+	tests := []struct {
+		mode   string
+		energy int
+		want   int
+		kept   bool
+	}{
+		{"normal", 50, 80, false},
+		{"normal", 90, 100, false},
+		{"normal", 100, 100, true}, // full energy keeps the cell
+		{"tutorial", 50, 50, true}, // energy never runs out anyway
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode+"/"+strconv.Itoa(tt.energy), func(t *testing.T) {
+			g, _ := newTestGame(t, tt.mode, 7)
+			g.Player.Stats.Energy = tt.energy
+			giveItem(g, "energy cell", true, ItemEffect{Kind: EffectEnergy, Amount: 30})
+			run(g, "use energy cell")
+			if got := g.Player.Stats.Energy; got != tt.want {
+				t.Errorf("energy %d, want %d", got, tt.want)
+			}
+			if g.Player.hasItem("energy cell") != tt.kept {
+				t.Errorf("energy cell kept = %v, want %v", !tt.kept, tt.kept)
+			}
+		})
+	}
+}
+
+func TestUnlockEffect(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	run(g, "go north")
+	bay := g.Player.CurrentRoom
+
+	run(g, "go north")
+	if g.Player.CurrentRoom != bay {
+		t.Fatal("walked through the locked door to the supply closet")
+	}
+	run(g, "take key", "use key on north", "go north")
+	if g.Player.CurrentRoom != g.Rooms["supply closet"] {
+		t.Fatalf("player is in %q after unlocking, want the supply closet", g.Player.CurrentRoom.Name)
+	}
+	if _, locked := g.Rooms["supply closet"].Locks["south"]; locked {
+		t.Error("the way back stayed locked")
+	}
+}
+
+func TestRevealEffect(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	closet := g.Rooms["supply closet"]
+	g.Player.CurrentRoom = closet
+
+	if out := run(g, "take data chip"); g.Player.hasItem("data chip") {
+		t.Fatalf("took the hidden data chip: %q", out)
+	}
+	run(g, "take UV lamp", "use UV lamp", "take data chip")
+	if !g.Player.hasItem("data chip") {
+		t.Fatal("the UV lamp did not reveal the data chip")
+	}
+	if len(closet.Hidden) != 0 {
+		t.Errorf("the data chip is still hidden: %v", closet.Hidden)
+	}
+	if !g.Player.hasItem("UV lamp") {
+		t.Error("the UV lamp was used up")
+	}
+}
+
+func TestRoomRequires(t *testing.T) {
+	tests := []struct {
+		item  string
+		enter bool
+	}{
+		{"", false},
+		{"Антиграви-модуль", true},
+		{"Грави-контроллер", true}, // either of the items will do
+	}
+	for _, tt := range tests {
+		g, _ := newTestGame(t, "normal", 7)
+		g.Player.CurrentRoom = g.Rooms["observatory"]
+		if tt.item != "" {
+			giveItem(g, tt.item, false)
+		}
+		out := run(g, "go north")
+		if entered := g.Player.CurrentRoom == g.Rooms["zero-g hangar"]; entered != tt.enter {
+			t.Errorf("with %q: entered %v, want %v", tt.item, entered, tt.enter)
+		}
+		if !tt.enter && !strings.Contains(out, "can't enter the Zero-G Hangar without the Антиграви-модуль or the Грави-контроллер") {
+			t.Errorf("with nothing: %q", out)
+		}
+	}
+}
+
+func TestMapEffect(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	giveItem(g, "star chart", false, ItemEffect{Kind: EffectMap})
+	var rooms []MapRoomView
+	for _, ev := range g.ProcessCommand("use star chart").Events {
+		if ev.Kind == EventMap {
+			rooms = ev.Map
+		}
+	}
+	if len(rooms) != len(g.Rooms) {
+		t.Fatalf("map of %d rooms, want %d", len(rooms), len(g.Rooms))
+	}
+	for _, room := range rooms {
+		if room.Here != (room.Name == g.Player.CurrentRoom.Name) {
+			t.Errorf("%s: here = %v", room.Name, room.Here)
+		}
+		if room.Name == "Cyber Control Room" {
+			for _, exit := range room.Exits {
+				if (exit.Direction == "west") != (exit.LockedBy != "") {
+					t.Errorf("exit %s locked by %q", exit.Direction, exit.LockedBy)
+				}
+			}
+		}
+	}
+}
+
+func TestValidateEffect(t *testing.T) {
+	tests := []struct {
+		effect ItemEffect
+		valid  bool
+	}{
+		{ItemEffect{Kind: EffectUnlock}, true},
+		{ItemEffect{Kind: EffectEnergy, Amount: 30}, true},
+		{ItemEffect{Kind: EffectEnergy}, false},
+		{ItemEffect{Kind: EffectEnergy, Amount: maxEnergy + 1}, false},
+		{ItemEffect{Kind: EffectReveal, Target: "data chip"}, true},
+		{ItemEffect{Kind: EffectReveal}, false},
+		{ItemEffect{Kind: "teleport"}, false},
+	}
+	for _, tt := range tests {
+		if err := validateEffect(tt.effect); (err == nil) != tt.valid {
+			t.Errorf("%+v: error %v, want valid %v", tt.effect, err, tt.valid)
+		}
+	}
+}
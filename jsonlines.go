@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONRenderer writes every event as one JSON object per line, for
+// programs driving the game.
+type JSONRenderer struct {
+	enc *json.Encoder
+}
+
+// NewJSONRenderer creates a renderer writing JSON lines to out.
+func NewJSONRenderer(out io.Writer) *JSONRenderer {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	return &JSONRenderer{enc: enc}
+}
+
+// Render implements Renderer. Clearing the screen and pauses mean nothing
+// to a program and are left out.
+func (j *JSONRenderer) Render(events []Event) {
+	for _, ev := range contentEvents(events) {
+		j.enc.Encode(ev)
+	}
+}
+
+// contentEvents drops the events that only matter on a screen: clearing
+// it and pausing to read.
+func contentEvents(events []Event) []Event {
+	content := make([]Event, 0, len(events))
+	for _, ev := range events {
+		if ev.Kind != EventClear && ev.Kind != EventPause {
+			content = append(content, ev)
+		}
+	}
+	return content
+}
+
+// Prompt implements Renderer.
+func (j *JSONRenderer) Prompt(p Prompt) {
+	j.enc.Encode(struct {
+		Kind string `json:"kind"`
+		Prompt
+	}{"prompt", p})
+}
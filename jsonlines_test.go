@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestJSONRenderer(t *testing.T) {
+	var out bytes.Buffer
+	g, _ := newTestGame(t, "normal", 7)
+	play(g, lines("help", "", "quit"), NewJSONRenderer(&out), nil)
+
+	kinds := make(map[string]int)
+	var prompts []string
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var line struct {
+			Kind string `json:"kind"`
+			Mode string `json:"mode"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		kinds[line.Kind]++
+		if line.Kind == "prompt" {
+			prompts = append(prompts, line.Mode)
+		}
+	}
+
+	for _, kind := range []EventKind{EventClear, EventPause} {
+		if kinds[string(kind)] > 0 {
+			t.Errorf("%s events were written", kind)
+		}
+	}
+	for _, kind := range []EventKind{EventRoom, EventHelp} {
+		if kinds[string(kind)] == 0 {
+			t.Errorf("no %s event was written", kind)
+		}
+	}
+	// Help is a menu, so it waits for Enter before the next command
+	if want := []string{"command", "continue", "command"}; !reflect.DeepEqual(prompts, want) {
+		t.Errorf("prompts %q, want %q", prompts, want)
+	}
+}
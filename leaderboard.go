@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// leaderboardFile is where local games record their runs by default.
+const leaderboardFile = "leaderboard.json"
+
+// leaderboardSize is how many runs the leaderboard shows.
+const leaderboardSize = 10
+
+// LeaderboardEntry is one finished run.
+type LeaderboardEntry struct {
+	Name     string        `json:"name"`
+	Seed     int64         `json:"seed"`
+	Mode     string        `json:"mode"`
+	Score    int           `json:"score"`
+	Duration time.Duration `json:"duration"` // game time the run took
+	Outcome  string        `json:"outcome"`
+	Date     time.Time     `json:"date"`
+}
+
+// leaderboardFilter selects runs by mode and seed; zero values match all.
+type leaderboardFilter struct {
+	Mode string
+	Seed int64
+}
+
+func (f leaderboardFilter) match(e LeaderboardEntry) bool {
+	return (f.Mode == "" || e.Mode == f.Mode) && (f.Seed == 0 || e.Seed == f.Seed)
+}
+
+// String describes the filter for the leaderboard title.
+func (f leaderboardFilter) String() string {
+	var parts []string
+	if f.Mode != "" {
+		parts = append(parts, "mode "+f.Mode)
+	}
+	if f.Seed != 0 {
+		parts = append(parts, fmt.Sprintf("seed %d", f.Seed))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseLeaderboardFilter reads the arguments of the leaderboard command:
+// a mode name, a seed or both.
+func parseLeaderboardFilter(args []string) (leaderboardFilter, error) {
+	var f leaderboardFilter
+	for _, arg := range args {
+		if _, ok := findMode(arg); ok {
+			f.Mode = arg
+			continue
+		}
+		seed, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return f, fmt.Errorf("%q is neither a mode (%s) nor a seed", arg, modeNames())
+		}
+		f.Seed = seed
+	}
+	return f, nil
+}
+
+// readLeaderboard reads every run recorded in path. A missing file is an
+// empty leaderboard.
+func readLeaderboard(path string) ([]LeaderboardEntry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []LeaderboardEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return entries, nil
+}
+
+// addToLeaderboard records a run in path.
+func addToLeaderboard(path string, entry LeaderboardEntry) error {
+	entries, err := readLeaderboard(path)
+	if err != nil {
+		return err
+	}
+	entries = append(entries, entry)
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	// Write to a temporary file first so a crash cannot lose the board
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// topRuns returns the best runs matching f, highest score first and the
+// faster run first on a tie.
+func topRuns(entries []LeaderboardEntry, f leaderboardFilter, n int) []LeaderboardEntry {
+	var runs []LeaderboardEntry
+	for _, e := range entries {
+		if f.match(e) {
+			runs = append(runs, e)
+		}
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		if runs[i].Score != runs[j].Score {
+			return runs[i].Score > runs[j].Score
+		}
+		return runs[i].Duration < runs[j].Duration
+	})
+	if len(runs) > n {
+		runs = runs[:n]
+	}
+	return runs
+}
+
+// leaderboardEvent describes the top runs matching f.
+func leaderboardEvent(path string, f leaderboardFilter) (Event, error) {
+	entries, err := readLeaderboard(path)
+	if err != nil {
+		return Event{}, err
+	}
+	runs := topRuns(entries, f, leaderboardSize)
+	if runs == nil {
+		runs = []LeaderboardEntry{}
+	}
+	return Event{Kind: EventLeaderboard, Text: f.String(), Runs: runs}, nil
+}
+
+// ShowLeaderboard shows the best local runs, filtered by the mode or seed
+// in args.
+func (g *Game) ShowLeaderboard(args []string) {
+	f, err := parseLeaderboardFilter(args)
+	if err != nil {
+		g.message(EventError, err.Error())
+		return
+	}
+	path := g.Config.Leaderboard
+	if path == "" {
+		path = leaderboardFile
+	}
+	ev, err := leaderboardEvent(path, f)
+	if err != nil {
+		g.message(EventError, fmt.Sprintf("Could not read the leaderboard: %v", err))
+		return
+	}
+	g.emit(Event{Kind: EventClear})
+	g.emit(ev)
+	g.openMenu()
+}
+
+// recordRun adds the finished game to the leaderboard, if the game keeps
+// one, and tells the player where the run placed.
+func (g *Game) recordRun(end *EndView) {
+	path := g.Config.Leaderboard
+	if path == "" || g.world != nil {
+		return
+	}
+	name := g.Player.Name
+	if name == "" {
+		name = "Player"
+	}
+	entry := LeaderboardEntry{
+		Name:     name,
+		Seed:     g.Seed,
+		Mode:     end.Mode,
+		Score:    end.Score,
+		Duration: end.TimeTaken,
+		Outcome:  end.Outcome,
+		Date:     g.now(),
+	}
+	if err := addToLeaderboard(path, entry); err != nil {
+		g.message(EventWarning, fmt.Sprintf("Could not update the leaderboard: %v", err))
+		return
+	}
+
+	entries, _ := readLeaderboard(path)
+	for i, run := range topRuns(entries, leaderboardFilter{Mode: entry.Mode}, leaderboardSize) {
+		if run.Name == entry.Name && run.Score == entry.Score && run.Date.Equal(entry.Date) {
+			g.message(EventSuccess, fmt.Sprintf("🏆 Your run is #%d on the %s leaderboard!", i+1, entry.Mode))
+			return
+		}
+	}
+}
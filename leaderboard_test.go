@@ -0,0 +1,96 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLeaderboardFilter(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    leaderboardFilter
+		wantErr bool
+	}{
+		{nil, leaderboardFilter{}, false},
+		{[]string{"hardcore"}, leaderboardFilter{Mode: "hardcore"}, false},
+		{[]string{"42"}, leaderboardFilter{Seed: 42}, false},
+		{[]string{"42", "tutorial"}, leaderboardFilter{Mode: "tutorial", Seed: 42}, false},
+		{[]string{"fast"}, leaderboardFilter{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseLeaderboardFilter(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: error %v, want error %v", tt.args, err, tt.wantErr)
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("%v: filter %+v, want %+v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestTopRuns(t *testing.T) {
+	entries := []LeaderboardEntry{
+		{Name: "slow", Mode: "normal", Seed: 1, Score: 900, Duration: 40 * time.Minute},
+		{Name: "best", Mode: "normal", Seed: 2, Score: 1200, Duration: 30 * time.Minute},
+		{Name: "fast", Mode: "normal", Seed: 1, Score: 900, Duration: 20 * time.Minute},
+		{Name: "hard", Mode: "hardcore", Seed: 1, Score: 2000, Duration: 25 * time.Minute},
+	}
+	tests := []struct {
+		filter leaderboardFilter
+		n      int
+		want   []string
+	}{
+		{leaderboardFilter{}, 10, []string{"hard", "best", "fast", "slow"}},
+		{leaderboardFilter{Mode: "normal"}, 10, []string{"best", "fast", "slow"}},
+		{leaderboardFilter{Seed: 1}, 10, []string{"hard", "fast", "slow"}},
+		{leaderboardFilter{Mode: "normal", Seed: 1}, 1, []string{"fast"}},
+		{leaderboardFilter{Mode: "tutorial"}, 10, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, run := range topRuns(entries, tt.filter, tt.n) {
+			got = append(got, run.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%+v: runs %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestFinishedGamesAreRecorded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "board.json")
+	for _, mode := range []string{"normal", "hardcore", "normal"} {
+		cfg, _ := testConfig(t, mode, 42)
+		cfg.Leaderboard = path
+		g := NewGame(cfg)
+		g.Player.Quests[0].Solved = true
+		g.finish(OutcomeLost)
+	}
+
+	entries, err := readLeaderboard(path)
+	if err != nil {
+		t.Fatalf("readLeaderboard: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("%d runs recorded, want 3", len(entries))
+	}
+
+	cfg, _ := testConfig(t, "normal", 7)
+	cfg.Leaderboard = path
+	g := NewGame(cfg)
+	resp := g.HandleProtocol(ProtocolCommand{Cmd: "leaderboard", Mode: "hardcore"})
+	if !resp.OK {
+		t.Fatalf("leaderboard: %s", resp.Error)
+	}
+	var runs []LeaderboardEntry
+	for _, ev := range resp.Events {
+		if ev.Kind == EventLeaderboard {
+			runs = ev.Runs
+		}
+	}
+	if len(runs) != 1 || runs[0].Mode != "hardcore" || runs[0].Name != "tester" {
+		t.Errorf("hardcore runs %+v, want the one run of tester", runs)
+	}
+}
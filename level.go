@@ -0,0 +1,473 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// defaultLevel is the built-in world.
+//
+//go:embed levels/default.json
+var defaultLevel embed.FS
+
+// Level describes a world: its rooms, items and how the rooms connect.
+type Level struct {
+	Name  string    `json:"name"`
+	Start string    `json:"start"`
+	Rooms []roomDef `json:"rooms"`
+
+	// Rewards defines the items quests award, by reward name. A reward
+	// without a definition is a plain trophy.
+	Rewards []itemDef `json:"rewards"`
+
+	// Escape is the way out. Without it, solving every quest wins.
+	Escape *escapeDef `json:"escape"`
+
+	file      string // where the level was loaded from, for errors
+	roomLines []int  // line of every room in file
+}
+
+// roomLine returns the line of the i-th room in the level file, or 0 if it
+// is not known.
+func (l *Level) roomLine(i int) int {
+	if i < len(l.roomLines) {
+		return l.roomLines[i]
+	}
+	return 0
+}
+
+type roomDef struct {
+	Key         string    `json:"key"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	ASCII       []string  `json:"ascii"`
+	Items       []itemDef `json:"items"`
+	Hidden      []itemDef `json:"hidden"`   // items found by using a reveal item
+	Requires    []string  `json:"requires"` // items one of which must be carried to enter
+	Stations    []Station `json:"stations"` // objects where quests are played
+	Exits       []exitDef `json:"exits"`
+}
+
+type itemDef struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Usable      bool         `json:"usable"`
+	ASCII       []string     `json:"ascii"`
+	Consumable  bool         `json:"consumable"`
+	Effects     []ItemEffect `json:"effects"`
+}
+
+func (def itemDef) build() *Item {
+	return &Item{
+		Name:        def.Name,
+		Description: def.Description,
+		Usable:      def.Usable,
+		ASCII:       asciiArt(def.ASCII),
+		Consumable:  def.Consumable,
+		Effects:     def.Effects,
+	}
+}
+
+// exitDef is a one-way exit unless Back names the direction of the way back.
+// An exit with LockedBy stays closed until that item is used next to it.
+type exitDef struct {
+	Direction string `json:"direction"`
+	To        string `json:"to"`
+	Back      string `json:"back"`
+	LockedBy  string `json:"locked_by"`
+}
+
+// hides reports whether some room of the level hides an item called name.
+func (l *Level) hides(name string) bool {
+	for _, room := range l.Rooms {
+		for _, item := range room.Hidden {
+			if strings.EqualFold(item.Name, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reward returns the definition of the quest reward called name.
+func (l *Level) reward(name string) (itemDef, bool) {
+	for _, def := range l.Rewards {
+		if strings.EqualFold(def.Name, name) {
+			return def, true
+		}
+	}
+	return itemDef{}, false
+}
+
+// asciiArt joins art lines the way the built-in art is laid out.
+func asciiArt(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n" + strings.Join(lines, "\n")
+}
+
+// arrayLines returns the line of every element of the array stored under
+// field in the top-level JSON object in data.
+func arrayLines(data []byte, field string) []int {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+		if key != field {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil
+			}
+			continue
+		}
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			return nil
+		}
+		var lines []int
+		for dec.More() {
+			lines = append(lines, lineAt(data, skipSeparators(data, dec.InputOffset())))
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return lines
+			}
+		}
+		return lines
+	}
+	return nil
+}
+
+// LoadLevel reads and validates a level file. An empty path loads the
+// built-in level.
+func LoadLevel(path string) (*Level, error) {
+	var data []byte
+	var err error
+	name := path
+	if path == "" {
+		name = "levels/default.json"
+		data, err = defaultLevel.ReadFile(name)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var level Level
+	if err := dec.Decode(&level); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			return nil, LoadErrors{{File: name, Line: lineAt(data, syntaxErr.Offset), Msg: err.Error()}}
+		case errors.As(err, &typeErr):
+			return nil, LoadErrors{{File: name, Line: lineAt(data, typeErr.Offset), Msg: err.Error()}}
+		default:
+			return nil, LoadErrors{{File: name, Msg: err.Error()}}
+		}
+	}
+
+	level.file, level.roomLines = name, arrayLines(data, "rooms")
+	if errs := level.validate(name, level.roomLines, arrayLines(data, "rewards")); len(errs) > 0 {
+		return nil, errs
+	}
+	return &level, nil
+}
+
+// validate checks that every exit leads somewhere, that directions are not
+// declared twice, that every room can be reached from the start with the
+// items found on the way and that items and rewards only have valid
+// effects.
+func (l *Level) validate(file string, lines, rewardLines []int) LoadErrors {
+	var errs LoadErrors
+	lineIn := func(lines []int, i int) int {
+		if i < len(lines) {
+			return lines[i]
+		}
+		return 0
+	}
+	lineOf := func(i int) int { return lineIn(lines, i) }
+	errorf := func(line int, format string, args ...interface{}) {
+		errs = append(errs, LoadError{File: file, Line: line, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	index := make(map[string]int, len(l.Rooms))
+	for i, room := range l.Rooms {
+		if room.Key == "" {
+			errorf(lineOf(i), "room has no key")
+			continue
+		}
+		if first, dup := index[room.Key]; dup {
+			errorf(lineOf(i), "duplicate room %q (first defined on line %d)", room.Key, lineOf(first))
+			continue
+		}
+		index[room.Key] = i
+	}
+
+	if _, ok := index[l.Start]; !ok {
+		errorf(0, "start room %q does not exist", l.Start)
+	}
+	if l.Escape != nil {
+		for _, problem := range l.Escape.validate(index) {
+			errorf(0, "%s", problem)
+		}
+	}
+
+	// Collect every exit, including the implied way back, per room.
+	edges := make(map[string]map[string]exitDef, len(l.Rooms))
+	addEdge := func(line int, from, direction, to, lock string) {
+		if edges[from] == nil {
+			edges[from] = make(map[string]exitDef)
+		}
+		if existing, dup := edges[from][direction]; dup && existing.To != to {
+			errorf(line, "room %q has two exits %s (to %q and %q)", from, direction, existing.To, to)
+			return
+		}
+		edges[from][direction] = exitDef{Direction: direction, To: to, LockedBy: lock}
+	}
+
+	checkEffects := func(line int, where string, item itemDef) {
+		for _, effect := range item.Effects {
+			if err := validateEffect(effect); err != nil {
+				errorf(line, "%s item %q: %v", where, item.Name, err)
+			} else if effect.Kind == EffectReveal && !l.hides(effect.Target) {
+				errorf(line, "%s item %q reveals %q, which is not hidden in any room", where, item.Name, effect.Target)
+			}
+		}
+	}
+
+	hosted := make(map[int]string) // quest ID -> station playing it
+	rewards := make(map[string]bool, len(l.Rewards))
+	for i, reward := range l.Rewards {
+		line := lineIn(rewardLines, i)
+		name := strings.ToLower(reward.Name)
+		switch {
+		case name == "":
+			errorf(line, "reward has no name")
+		case rewards[name]:
+			errorf(line, "reward %q is defined twice", reward.Name)
+		}
+		rewards[name] = true
+		checkEffects(line, "reward", reward)
+	}
+
+	for i, room := range l.Rooms {
+		for _, item := range append(append([]itemDef(nil), room.Items...), room.Hidden...) {
+			checkEffects(lineOf(i), fmt.Sprintf("room %q", room.Key), item)
+		}
+		for _, name := range room.Requires {
+			if strings.TrimSpace(name) == "" {
+				errorf(lineOf(i), "room %q requires an item without a name", room.Key)
+			}
+		}
+		names := make(map[string]bool, len(room.Stations))
+		for _, station := range room.Stations {
+			switch key := strings.ToLower(station.Name); {
+			case strings.TrimSpace(key) == "":
+				errorf(lineOf(i), "room %q has a station without a name", room.Key)
+			case names[key]:
+				errorf(lineOf(i), "room %q has two stations called %q", room.Key, station.Name)
+			}
+			names[strings.ToLower(station.Name)] = true
+			for _, id := range station.Quests {
+				if where, dup := hosted[id]; dup {
+					errorf(lineOf(i), "quest %d is played at both %s and %q in room %q", id, where, station.Name, room.Key)
+				}
+				hosted[id] = fmt.Sprintf("%q in room %q", station.Name, room.Key)
+			}
+		}
+		for _, exit := range room.Exits {
+			if exit.Direction == "" {
+				errorf(lineOf(i), "room %q has an exit without a direction", room.Key)
+				continue
+			}
+			if _, ok := index[exit.To]; !ok {
+				errorf(lineOf(i), "room %q exit %s leads to unknown room %q", room.Key, exit.Direction, exit.To)
+				continue
+			}
+			addEdge(lineOf(i), room.Key, exit.Direction, exit.To, exit.LockedBy)
+			if exit.Back != "" {
+				addEdge(lineOf(i), exit.To, exit.Back, room.Key, exit.LockedBy)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	open := l.reachable(edges, func(string) bool { return true })
+	reached := l.reachable(edges, l.obtainable(edges))
+
+	var unreachable []string
+	for key := range index {
+		if !reached[key] {
+			unreachable = append(unreachable, key)
+		}
+	}
+	sort.Strings(unreachable)
+	for _, key := range unreachable {
+		if open[key] {
+			errorf(lineOf(index[key]), "room %q cannot be reached from %q: the items that open the way are only found behind it", key, l.Start)
+		} else {
+			errorf(lineOf(index[key]), "room %q cannot be reached from %q", key, l.Start)
+		}
+	}
+
+	return errs
+}
+
+// reachable returns the rooms the player can walk to from the start,
+// through exits whose lock have opens into rooms whose requirements have
+// meets.
+func (l *Level) reachable(edges map[string]map[string]exitDef, have func(name string) bool) map[string]bool {
+	requires := make(map[string][]string, len(l.Rooms))
+	for _, room := range l.Rooms {
+		requires[room.Key] = room.Requires
+	}
+	canEnter := func(key string) bool {
+		for _, name := range requires[key] {
+			if have(name) {
+				return true
+			}
+		}
+		return len(requires[key]) == 0
+	}
+
+	reached := map[string]bool{l.Start: true}
+	queue := []string{l.Start}
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		for _, exit := range edges[key] {
+			if reached[exit.To] || (exit.LockedBy != "" && !have(exit.LockedBy)) || !canEnter(exit.To) {
+				continue
+			}
+			reached[exit.To] = true
+			queue = append(queue, exit.To)
+		}
+	}
+	return reached
+}
+
+// obtainable returns which items the player can get hold of. Items no room
+// holds come from quests and are always at hand; an item in a room only
+// once the room can be reached, and a hidden one once something that
+// reveals it is at hand too.
+func (l *Level) obtainable(edges map[string]map[string]exitDef) func(name string) bool {
+	inRooms := make(map[string]bool)
+	revealers := make(map[string][]string) // hidden item -> items revealing it
+	addRevealers := func(items []itemDef) {
+		for _, item := range items {
+			for _, effect := range item.Effects {
+				if effect.Kind == EffectReveal {
+					target := strings.ToLower(effect.Target)
+					revealers[target] = append(revealers[target], item.Name)
+				}
+			}
+		}
+	}
+	for _, room := range l.Rooms {
+		for _, item := range append(append([]itemDef(nil), room.Items...), room.Hidden...) {
+			inRooms[strings.ToLower(item.Name)] = true
+		}
+		addRevealers(room.Items)
+		addRevealers(room.Hidden)
+	}
+	addRevealers(l.Rewards)
+
+	got := make(map[string]bool)
+	have := func(name string) bool {
+		name = strings.ToLower(name)
+		return !inRooms[name] || got[name]
+	}
+
+	// Every new item may open more rooms, which may hold more items
+	for {
+		before := len(got)
+		reached := l.reachable(edges, have)
+		for _, room := range l.Rooms {
+			if !reached[room.Key] {
+				continue
+			}
+			for _, item := range room.Items {
+				got[strings.ToLower(item.Name)] = true
+			}
+			for _, item := range room.Hidden {
+				for _, revealer := range revealers[strings.ToLower(item.Name)] {
+					if have(revealer) {
+						got[strings.ToLower(item.Name)] = true
+					}
+				}
+			}
+		}
+		if len(got) == before {
+			return have
+		}
+	}
+}
+
+// Build creates a fresh set of rooms for the level and returns them keyed
+// by room key, together with the starting room.
+func (l *Level) Build() (map[string]*Room, *Room) {
+	rooms := make(map[string]*Room, len(l.Rooms))
+	for _, def := range l.Rooms {
+		items := make([]*Item, 0, len(def.Items))
+		for _, item := range def.Items {
+			items = append(items, item.build())
+		}
+		var hidden []*Item
+		for _, item := range def.Hidden {
+			hidden = append(hidden, item.build())
+		}
+		var stations []*Station
+		for _, station := range def.Stations {
+			station := station
+			stations = append(stations, &station)
+		}
+		rooms[def.Key] = &Room{
+			Name:        def.Name,
+			Description: def.Description,
+			Items:       items,
+			Hidden:      hidden,
+			Requires:    def.Requires,
+			Stations:    stations,
+			Exits:       make(map[string]*Room),
+			Locks:       make(map[string]string),
+			ASCII:       asciiArt(def.ASCII),
+		}
+	}
+
+	for _, def := range l.Rooms {
+		room := rooms[def.Key]
+		for _, exit := range def.Exits {
+			target := rooms[exit.To]
+			room.Exits[exit.Direction] = target
+			if exit.LockedBy != "" {
+				room.Locks[exit.Direction] = exit.LockedBy
+			}
+			if exit.Back != "" {
+				target.Exits[exit.Back] = room
+				if exit.LockedBy != "" {
+					target.Locks[exit.Back] = exit.LockedBy
+				}
+			}
+		}
+	}
+
+	return rooms, rooms[l.Start]
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultLevelBuilds(t *testing.T) {
+	level, err := LoadLevel("")
+	if err != nil {
+		t.Fatalf("LoadLevel: %v", err)
+	}
+	rooms, start := level.Build()
+	if start != rooms[level.Start] || len(rooms) != len(level.Rooms) {
+		t.Fatalf("built %d rooms starting in %q", len(rooms), start.Name)
+	}
+	control, bay := rooms["cyber control room"], rooms["engineering bay"]
+	if control.Exits["north"] != bay || bay.Exits["south"] != control {
+		t.Error("the engineering bay is not linked both ways to the control room")
+	}
+	if bay.Locks["north"] != "key" || rooms["supply closet"].Locks["south"] != "key" {
+		t.Error("the supply closet door is not locked by the key on both sides")
+	}
+}
+
+func TestLevelReachability(t *testing.T) {
+	tests := []struct {
+		name  string
+		rooms string
+		want  string // the error, empty if the level is valid
+	}{
+		{
+			name: "key in reach",
+			rooms: `{"key": "hall", "items": [{"name": "key"}], "exits": [{"direction": "north", "to": "vault", "back": "south", "locked_by": "key"}]},
+    {"key": "vault"}`,
+		},
+		{
+			name: "key behind its own door",
+			rooms: `{"key": "hall", "exits": [{"direction": "north", "to": "vault", "back": "south", "locked_by": "key"}]},
+    {"key": "vault", "items": [{"name": "key"}]}`,
+			want: `room "vault" cannot be reached from "hall": the items that open the way are only found behind it`,
+		},
+		{
+			name: "key in a room that needs it",
+			rooms: `{"key": "hall", "exits": [{"direction": "north", "to": "vault", "back": "south"}]},
+    {"key": "vault", "requires": ["badge"], "items": [{"name": "badge"}]}`,
+			want: `room "vault" cannot be reached from "hall": the items that open the way are only found behind it`,
+		},
+		{
+			name: "a chain of keys",
+			rooms: `{"key": "hall", "items": [{"name": "red key"}], "exits": [{"direction": "north", "to": "lab", "back": "south", "locked_by": "red key"}]},
+    {"key": "lab", "hidden": [{"name": "blue key"}], "items": [{"name": "lamp", "effects": [{"kind": "reveal", "target": "blue key"}]}],
+     "exits": [{"direction": "north", "to": "vault", "back": "south", "locked_by": "blue key"}]},
+    {"key": "vault"}`,
+		},
+		{
+			name: "a quest reward opens the way",
+			rooms: `{"key": "hall", "exits": [{"direction": "north", "to": "vault", "back": "south", "locked_by": "Кибер-ключ"}]},
+    {"key": "vault", "requires": ["Кибер-ключ"]}`,
+		},
+		{
+			name: "no way at all",
+			rooms: `{"key": "hall"},
+    {"key": "island"}`,
+			want: `room "island" cannot be reached from "hall"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "level.json")
+			data := `{"name": "test", "start": "hall", "rooms": [` + tt.rooms + `]}`
+			if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadLevel(path)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("LoadLevel: %v", err)
+			case tt.want != "" && err == nil:
+				t.Errorf("LoadLevel accepted the level, want %q", tt.want)
+			case tt.want != "" && !strings.HasSuffix(err.Error(), tt.want):
+				t.Errorf("error %q, want %q", err, tt.want)
+			}
+		})
+	}
+}
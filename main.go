@@ -2,27 +2,17 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"math/rand"
+	"net"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 )
 
-// ANSI Color codes
-const (
-	ColorReset  = "\033[0m"
-	ColorRed    = "\033[31m"
-	ColorGreen  = "\033[32m"
-	ColorYellow = "\033[33m"
-	ColorBlue   = "\033[34m"
-	ColorPurple = "\033[35m"
-	ColorCyan   = "\033[36m"
-	ColorWhite  = "\033[37m"
-	ColorBold   = "\033[1m"
-)
-
 // QuestCategory represents different types of quests
 type QuestCategory int
 
@@ -47,28 +37,37 @@ type Quest struct {
 	Requirements []string
 	Solution     string
 	ASCII        string
-	Hints        []string // Подсказки для квеста
-	Example      string   // Пример решения
+	Hints        []string      // Подсказки для квеста
+	Example      string        // Пример решения
+	Answers      []string      // Другие принятые ответы
+	Match        AnswerMatch   // Как сравнивать ответы
+	Checker      AnswerChecker // Проверка ответа, собранная из Solution, Answers и Match
+	Coop         *CoopRule     // Правила совместного решения, если квест кооперативный
+	Generator    string        // Генератор, создающий головоломку, если квест процедурный
+	Seed         int64         // Зерно, из которого сгенерирован экземпляр квеста
 }
 
 // PlayerStats represents player characteristics
 type PlayerStats struct {
-	Hacking     int // 0-100
-	Engineering int // 0-100
-	Astronomy   int // 0-100
-	Biology     int // 0-100
-	Physics     int // 0-100
-	Energy      int // 0-100
-	TimeLeft    time.Duration
+	Hacking     int           `json:"hacking"`     // 0-100
+	Engineering int           `json:"engineering"` // 0-100
+	Astronomy   int           `json:"astronomy"`   // 0-100
+	Biology     int           `json:"biology"`     // 0-100
+	Physics     int           `json:"physics"`     // 0-100
+	Energy      int           `json:"energy"`      // 0-100
+	TimeLeft    time.Duration `json:"time_left"`
+	XP          int           `json:"xp"` // experience earned in all categories
 }
 
 // Item represents an object in the game
 type Item struct {
-	Name        string
-	Description string
-	Usable      bool
-	ASCII       string
-	QuestID     int // Associated quest ID
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Usable      bool         `json:"usable"`
+	ASCII       string       `json:"ascii"`
+	QuestID     int          `json:"quest_id"`             // Associated quest ID
+	Consumable  bool         `json:"consumable,omitempty"` // used up once one of its effects worked
+	Effects     []ItemEffect `json:"effects,omitempty"`
 }
 
 // Room represents a location in the game
@@ -77,17 +76,25 @@ type Room struct {
 	Description string
 	Items       []*Item
 	Exits       map[string]*Room
+	Locks       map[string]string // direction -> item that unlocks it
+	Hidden      []*Item           // items only found by revealing them
+	Requires    []string          // items one of which must be carried to enter
+	Stations    []*Station        // interactive objects where quests are played
 	Solved      bool
 	ASCII       string
 }
 
 // Player represents the game player
 type Player struct {
-	CurrentRoom *Room
-	Inventory   []*Item
-	Stats       *PlayerStats
-	Quests      []*Quest
-	Completed   int
+	Name          string // shown to other players in multiplayer
+	CurrentRoom   *Room
+	Inventory     []*Item
+	Stats         *PlayerStats
+	Quests        []*Quest
+	Completed     int
+	HintsUsed     map[int]int // quest ID -> hints revealed
+	CoopCredit    map[int]int // co-op quest ID -> part the player entered
+	WrongAttempts int         // wrong or late answers, which lower the score
 }
 
 // Game represents the main game state
@@ -97,204 +104,21 @@ type Game struct {
 	AllQuests []*Quest
 	GameStart time.Time
 	GameMode  string // "tutorial", "normal", "hardcore"
-}
+	Seed      int64  // seed of every random choice in this game
+
+	Config GameConfig
 
-// UI Helper functions
-func clearScreen() {
-	fmt.Print("\033[2J\033[H")
-}
-
-func printBanner() {
-	banner := `
-╔══════════════════════════════════════════════════════════════╗
-║                                                              ║
-║  ██████╗ ██████╗ ███████╗███╗   ███╗██╗   ██╗██████╗ ██████╗ ║
-║ ██╔════╝██╔═══██╗██╔════╝████╗ ████║██║   ██║██╔══██╗██╔══██╗║
-║ ██║     ██║   ██║███████╗██╔████╔██║██║   ██║██████╔╝██████╔╝║
-║ ██║     ██║   ██║╚════██║██║╚██╔╝██║██║   ██║██╔═══╝ ██╔══██╗║
-║ ╚██████╗╚██████╔╝███████║██║ ╚═╝ ██║╚██████╔╝██║     ██║  ██║║
-║  ╚═════╝ ╚═════╝ ╚══════╝╚═╝     ╚═╝ ╚═════╝ ╚═╝     ╚═╝  ╚═╝║
-║                                                              ║
-║              🌌 COSMIC CYBERPUNK ROOM ESCAPE 🌌             ║
-║                                                              ║
-╚══════════════════════════════════════════════════════════════╝`
-	fmt.Printf("%s%s\n", ColorCyan, banner)
-}
-
-func printSeparator() {
-	fmt.Printf("%s%s%s\n", ColorBlue, strings.Repeat("═", 70), ColorReset)
-}
-
-func printColored(text, color string) {
-	fmt.Printf("%s%s%s", color, text, ColorReset)
-}
-
-func printSuccess(message string) {
-	fmt.Printf("%s✅ %s%s\n", ColorGreen, message, ColorReset)
-}
-
-func printWarning(message string) {
-	fmt.Printf("%s⚠️  %s%s\n", ColorYellow, message, ColorReset)
-}
-
-func printError(message string) {
-	fmt.Printf("%s❌ %s%s\n", ColorRed, message, ColorReset)
-}
-
-func printInfo(message string) {
-	fmt.Printf("%sℹ️  %s%s\n", ColorCyan, message, ColorReset)
-}
-
-func printASCII(ascii string) {
-	fmt.Printf("%s%s%s\n", ColorPurple, ascii, ColorReset)
-}
-
-// Quest creation functions
-func createAllQuests() []*Quest {
-	quests := []*Quest{
-		// Хакерские и кибернетические задачи (1-20)
-		{1, "Взлом голограммы", "Расшифровать двоичный код, проецируемый голографическим интерфейсом", HackerQuest, 2, false, 5 * time.Minute, "Кибер-ключ", []string{"Голографический терминал"}, "01001000 01100001 01100011 01101011", `
-    ╔══════════════════════════════╗
-    ║  🔮 HOLOGRAM INTERFACE 🔮    ║
-    ║  01001000 01100001 01100011  ║
-    ║  01101011 01100101 01110010  ║
-    ╚══════════════════════════════╝`,
-			[]string{
-				"💡 Подсказка 1: Это двоичный код. Каждая группа из 8 цифр представляет одну букву.",
-				"💡 Подсказка 2: Переведите двоичный код в текст. 01001000 = H, 01100001 = a, 01100011 = c, 01101011 = k",
-				"💡 Подсказка 3: Слово 'Hacker' на английском языке.",
-			}, "Пример: 01001000 = H, 01100001 = a → 'Ha'"},
-
-		{2, "Нейроинтерфейс", "Подключиться к мозговому чипу и решить математическую последовательность", HackerQuest, 3, false, 7 * time.Minute, "Нейро-имплант", []string{"Нейро-шлем"}, "2, 4, 8, 16, 32, 64", `
-    ╔══════════════════════════════╗
-    ║  🧠 NEURO INTERFACE 🧠        ║
-    ║  [2] [4] [8] [16] [32] [64]  ║
-    ║  Find the next number...     ║
-    ╚══════════════════════════════╝`,
-			[]string{
-				"💡 Подсказка 1: Каждое число в 2 раза больше предыдущего.",
-				"💡 Подсказка 2: 2×2=4, 4×2=8, 8×2=16, 16×2=32, 32×2=64",
-				"💡 Подсказка 3: Следующее число: 64×2 = ?",
-			}, "Пример: 2 → 4 → 8 → 16 → 32 → 64 → 128"},
-
-		{3, "Квантовый пароль", "Одновременно активировать несколько терминалов в правильной последовательности", HackerQuest, 4, false, 10 * time.Minute, "Квантовый ключ", []string{"Терминал 1", "Терминал 2", "Терминал 3"}, "1-3-2-1-3", `
-    ╔══════════════════════════════╗
-    ║  ⚛️ QUANTUM TERMINALS ⚛️      ║
-    ║  [1] [2] [3]                 ║
-    ║  Activate in sequence...     ║
-    ╚══════════════════════════════╝`,
-			[]string{
-				"💡 Подсказка 1: Последовательность: 1, затем 3, затем 2, затем 1, затем 3.",
-				"💡 Подсказка 2: Начните с терминала 1, затем перейдите к терминалу 3.",
-				"💡 Подсказка 3: Полная последовательность: 1-3-2-1-3",
-			}, "Пример: Активируйте терминалы в порядке 1, 3, 2, 1, 3"},
-
-		// Инженерные и технические головоломки (21-40)
-		{21, "Энергетические узлы", "Перенаправить поток энергии через сложную схему", EngineeringQuest, 3, false, 8 * time.Minute, "Энерго-модуль", []string{"Энергетическая сеть"}, "A→B→C→D→E", `
-    ╔══════════════════════════════╗
-    ║  ⚡ ENERGY GRID ⚡            ║
-    ║  A ── B ── C ── D ── E       ║
-    ║  │    │    │    │    │       ║
-    ║  F ── G ── H ── I ── J       ║
-    ╚══════════════════════════════╝`,
-			[]string{
-				"💡 Подсказка 1: Следуйте по верхней линии: A → B → C → D → E",
-				"💡 Подсказка 2: Не переходите на нижнюю линию (F-G-H-I-J)",
-				"💡 Подсказка 3: Простое линейное соединение: A→B→C→D→E",
-			}, "Пример: Начните с A, затем B, затем C, затем D, затем E"},
-
-		{22, "Гравитационный генератор", "Настроить искусственную гравитацию в нужных зонах", EngineeringQuest, 4, false, 12 * time.Minute, "Грави-контроллер", []string{"Генератор гравитации"}, "Zone1: 0.5g, Zone2: 1.0g, Zone3: 1.5g", `
-    ╔══════════════════════════════╗
-    ║  🌍 GRAVITY GENERATOR 🌍     ║
-    ║  Zone 1: [0.5g]              ║
-    ║  Zone 2: [1.0g]              ║
-    ║  Zone 3: [1.5g]              ║
-    ╚══════════════════════════════╝`,
-			[]string{
-				"💡 Подсказка 1: Установите гравитацию: Зона 1 = 0.5g, Зона 2 = 1.0g, Зона 3 = 1.5g",
-				"💡 Подсказка 2: Формат ответа: 'Zone1: 0.5g, Zone2: 1.0g, Zone3: 1.5g'",
-				"💡 Подсказка 3: Постепенное увеличение гравитации от 0.5 до 1.5",
-			}, "Пример: Zone1: 0.5g, Zone2: 1.0g, Zone3: 1.5g"},
-
-		// Астрономические и космические загадки (41-60)
-		{41, "Звездная карта", "Найти правильное созвездие для навигации", AstronomicalQuest, 2, false, 6 * time.Minute, "Навигационный чип", []string{"Звездная карта"}, "Орион", `
-    ╔══════════════════════════════╗
-    ║  ⭐ STAR MAP ⭐              ║
-    ║  • • • • • • • • • • • • •   ║
-    ║  • • • • • • • • • • • • •   ║
-    ║  • • • • • • • • • • • • •   ║
-    ╚══════════════════════════════╝`,
-			[]string{
-				"💡 Подсказка 1: Это одно из самых известных созвездий в северном полушарии.",
-				"💡 Подсказка 2: Созвездие с тремя яркими звездами в ряд (пояс охотника).",
-				"💡 Подсказка 3: Название созвездия: 'Орион' (Orion)",
-			}, "Пример: Орион - одно из самых узнаваемых созвездий"},
-
-		{42, "Планетарное выравнивание", "Дождаться, когда планеты займут нужные позиции", AstronomicalQuest, 3, false, 15 * time.Minute, "Планетарный сканер", []string{"Планетарный симулятор"}, "Mercury-Venus-Earth-Mars", `
-    ╔══════════════════════════════╗
-    ║  🪐 PLANETARY ALIGNMENT 🪐   ║
-    ║  ☿️  ♀️  🌍  ♂️  ♃️  ♄️  ║
-    ║  Wait for alignment...       ║
-    ╚══════════════════════════════╝`,
-			[]string{
-				"💡 Подсказка 1: Планеты должны выстроиться в порядке от Солнца: Меркурий, Венера, Земля, Марс",
-				"💡 Подсказка 2: Формат ответа: 'Mercury-Venus-Earth-Mars'",
-				"💡 Подсказка 3: Четыре ближайшие к Солнцу планеты в правильном порядке",
-			}, "Пример: Mercury-Venus-Earth-Mars - планеты в порядке от Солнца"},
-
-		// Биологические и медицинские задачи (61-80)
-		{61, "Генетический замок", "Модифицировать ДНК для доступа к биосейфу", BiologicalQuest, 4, false, 10 * time.Minute, "Генетический ключ", []string{"ДНК-анализатор"}, "ATCGATCGATCG", `
-    ╔══════════════════════════════╗
-    ║  🧬 DNA LOCK 🧬              ║
-    ║  A T C G A T C G A T C G     ║
-    ║  Modify sequence...          ║
-    ╚══════════════════════════════╝`,
-			[]string{
-				"💡 Подсказка 1: Повторите последовательность A-T-C-G три раза подряд",
-				"💡 Подсказка 2: ATCG + ATCG + ATCG = ATCGATCGATCG",
-				"💡 Подсказка 3: Полная последовательность: ATCGATCGATCG",
-			}, "Пример: ATCGATCGATCG - повторение базовой последовательности"},
-
-		{62, "Синтетические органы", "Подключить искусственные органы к пациенту", BiologicalQuest, 5, false, 15 * time.Minute, "Био-имплант", []string{"Синтетическое сердце", "Нейронные связи"}, "Heart→Brain→Lungs→Liver", `
-    ╔══════════════════════════════╗
-    ║  🫀 SYNTHETIC ORGANS 🫀      ║
-    ║  Heart → Brain → Lungs       ║
-    ║  Connect in sequence...      ║
-    ╚══════════════════════════════╝`,
-			[]string{
-				"💡 Подсказка 1: Подключите органы в порядке: Сердце → Мозг → Легкие → Печень",
-				"💡 Подсказка 2: Формат ответа: 'Heart→Brain→Lungs→Liver'",
-				"💡 Подсказка 3: Начните с сердца, затем мозг, затем легкие, затем печень",
-			}, "Пример: Heart→Brain→Lungs→Liver - последовательность подключения органов"},
-
-		// Физические и механические головоломки (81-100)
-		{81, "Левитирующие платформы", "Управлять парящими в воздухе поверхностями", PhysicalQuest, 3, false, 8 * time.Minute, "Антиграви-модуль", []string{"Платформа-контроллер"}, "Platform1→Platform2→Platform3", `
-    ╔══════════════════════════════╗
-    ║  🏗️ FLOATING PLATFORMS 🏗️   ║
-    ║  [1]     [2]     [3]         ║
-    ║  ╱╲     ╱╲     ╱╲            ║
-    ║  Navigate sequence...        ║
-    ╚══════════════════════════════╝`,
-			[]string{
-				"💡 Подсказка 1: Перемещайтесь по платформам в порядке: 1 → 2 → 3",
-				"💡 Подсказка 2: Формат ответа: 'Platform1→Platform2→Platform3'",
-				"💡 Подсказка 3: Простая последовательность: Платформа 1, затем 2, затем 3",
-			}, "Пример: Platform1→Platform2→Platform3 - последовательность навигации"},
-
-		{82, "Голографические стены", "Отличить настоящие препятствия от иллюзий", PhysicalQuest, 4, false, 12 * time.Minute, "Голо-детектор", []string{"Голографический проектор"}, "Real: 1,3,5,7,9", `
-    ╔══════════════════════════════╗
-    ║  🎭 HOLOGRAPHIC WALLS 🎭     ║
-    ║  [1][2][3][4][5][6][7][8][9] ║
-    ║  Find the real ones...       ║
-    ╚══════════════════════════════╝`,
-			[]string{
-				"💡 Подсказка 1: Настоящие стены имеют нечетные номера: 1, 3, 5, 7, 9",
-				"💡 Подсказка 2: Формат ответа: 'Real: 1,3,5,7,9'",
-				"💡 Подсказка 3: Все нечетные числа от 1 до 9 являются настоящими стенами",
-			}, "Пример: Real: 1,3,5,7,9 - нечетные номера стен"},
-	}
-
-	return quests
+	events      []Event // output of the command being processed
+	activeQuest *Quest  // quest waiting for an answer
+	attempt     questAttempt
+	menuOpen    bool // a menu screen is shown until the next input
+	saveUsed    bool // a single-save game was loaded from its save
+	clock       gameClock
+	outcome     Outcome
+	stepTime    time.Time // time of the step being processed
+
+	world *World       // shared world in multiplayer, nil otherwise
+	inbox chan []Event // messages from other players
 }
 
 func getCategoryName(category QuestCategory) string {
@@ -331,97 +155,33 @@ func getCategoryEmoji(category QuestCategory) string {
 	}
 }
 
+// GameConfig holds everything needed to start a new game
+type GameConfig struct {
+	Level  *Level
+	Quests []*Quest
+	Clock  ClockConfig
+	Seed   int64  // 0 picks a random seed
+	Mode   string // game mode, "normal" if empty
+
+	Name        string // the player's name on the leaderboard
+	Leaderboard string // file finished games are recorded in; none if empty
+	SaveDir     string // directory of the save slots, "saves" if empty
+}
+
 // NewGame creates a new game instance
-func NewGame() *Game {
-	// Create all quests
-	allQuests := createAllQuests()
-
-	// Create items
-	key := &Item{
-		Name:        "key",
-		Description: "A rusty old key that might fit somewhere",
-		Usable:      true,
-		ASCII: `
-    ╔══════╗
-    ║  🔑  ║
-    ╚══════╝`,
-		QuestID: 0,
-	}
-
-	note := &Item{
-		Name:        "note",
-		Description: "A crumpled note with numbers: 1234",
-		Usable:      false,
-		ASCII: `
-    ╔══════════╗
-    ║  📄 NOTE ║
-    ║    1234  ║
-    ╚══════════╝`,
-		QuestID: 0,
-	}
-
-	// Create cyberpunk rooms
-	cyberRoom := &Room{
-		Name:        "Cyber Control Room",
-		Description: "A high-tech control room filled with holographic displays and quantum computers. The air hums with energy.",
-		Items:       []*Item{note},
-		Exits:       make(map[string]*Room),
-		Solved:      false,
-		ASCII: `
-    ┌─────────────────────────────────┐
-    │  💻    🔮    🧠    ⚛️         │
-    │                                 │
-    │        🚪        🚪             │
-    │                                 │
-    │  📡    📊    📈    📉          │
-    └─────────────────────────────────┘`,
-	}
-
-	engineeringBay := &Room{
-		Name:        "Engineering Bay",
-		Description: "A massive engineering facility with gravity generators, energy nodes, and plasma resonators.",
-		Items:       []*Item{key},
-		Exits:       make(map[string]*Room),
-		Solved:      false,
-		ASCII: `
-    ┌─────────────────────────────────┐
-    │  ⚡    🌍    ⚙️    🔧           │
-    │                                 │
-    │        🚪        🚪             │
-    │                                 │
-    │  ⚙️    🔩    ⚡    🌍          │
-    └─────────────────────────────────┘`,
-	}
-
-	observatory := &Room{
-		Name:        "Space Observatory",
-		Description: "A domed observatory with star maps, planetary simulators, and cosmic navigation equipment.",
-		Items:       []*Item{},
-		Exits:       make(map[string]*Room),
-		Solved:      false,
-		ASCII: `
-    ┌─────────────────────────────────┐
-    │  ⭐    🪐    🌟    🌌           │
-    │                                 │
-    │        🚪        🚪             │
-    │                                 │
-    │  🔭    📡    🛰️    🚀          │
-    └─────────────────────────────────┘`,
-	}
-
-	// Set up room connections
-	cyberRoom.Exits["north"] = engineeringBay
-	cyberRoom.Exits["east"] = observatory
-	engineeringBay.Exits["south"] = cyberRoom
-	engineeringBay.Exits["east"] = observatory
-	observatory.Exits["west"] = cyberRoom
-	observatory.Exits["south"] = engineeringBay
-
-	rooms := map[string]*Room{
-		"cyber control room": cyberRoom,
-		"engineering bay":    engineeringBay,
-		"observatory":        observatory,
+func NewGame(cfg GameConfig) *Game {
+	if cfg.Clock.Budget == 0 {
+		cfg.Clock = DefaultClockConfig()
+	}
+	if cfg.Clock.Now == nil {
+		cfg.Clock.Now = time.Now
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = defaultMode
 	}
+	gameStart := cfg.Clock.Now()
+
+	rooms, start := cfg.Level.Build()
 
 	// Create player with stats
 	playerStats := &PlayerStats{
@@ -431,79 +191,115 @@ func NewGame() *Game {
 		Biology:     50,
 		Physics:     50,
 		Energy:      100,
-		TimeLeft:    60 * time.Minute, // 1 hour game time
+		TimeLeft:    cfg.Clock.Budget,
 	}
 
-	// Select random quests for the player
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	// The seed drives every random choice, so the same seed and the same
+	// commands replay the same game
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	r := rand.New(rand.NewSource(seed))
+
+	// Every game plays its own copies of the quests, with procedural ones
+	// generated from their own seed
+	allQuests := make([]*Quest, len(cfg.Quests))
+	for i, quest := range cfg.Quests {
+		allQuests[i] = quest.Generate(r.Int63())
+	}
+
+	// Select 5 random quests, each drawn together with the quests it
+	// requires to be solved first
 	playerQuests := make([]*Quest, 0)
 	questIndices := r.Perm(len(allQuests))
 
-	// Select 5 random quests
-	for i := 0; i < 5 && i < len(questIndices); i++ {
-		quest := allQuests[questIndices[i]]
-		playerQuests = append(playerQuests, quest)
+	byID := make(map[int]*Quest, len(allQuests))
+	for _, quest := range allQuests {
+		byID[quest.ID] = quest
+	}
+	selected := make(map[int]bool)
+	for _, index := range questIndices {
+		if len(playerQuests) >= 5 {
+			break
+		}
+		group := withPrerequisites(allQuests[index], byID, selected)
+		if len(playerQuests)+len(group) > 5 {
+			continue
+		}
+		for _, quest := range group {
+			selected[quest.ID] = true
+			playerQuests = append(playerQuests, quest)
+		}
 	}
 
 	player := &Player{
-		CurrentRoom: cyberRoom,
+		Name:        cfg.Name,
+		CurrentRoom: start,
 		Inventory:   []*Item{},
 		Stats:       playerStats,
 		Quests:      playerQuests,
 		Completed:   0,
+		HintsUsed:   make(map[int]int),
+		CoopCredit:  make(map[int]int),
 	}
 
-	return &Game{
+	g := &Game{
 		Player:    player,
 		Rooms:     rooms,
 		AllQuests: allQuests,
-		GameStart: time.Now(),
-		GameMode:  "normal",
+		GameStart: gameStart,
+		GameMode:  cfg.Mode,
+		Seed:      seed,
+		Config:    cfg,
+		stepTime:  gameStart,
 	}
+	g.syncClock()
+	return g
 }
 
-// Look displays the current room description and items
-func (g *Game) Look() {
-	clearScreen()
-
-	// Print room ASCII art
-	printASCII(g.Player.CurrentRoom.ASCII)
-
-	printSeparator()
-	printColored(fmt.Sprintf("📍 %s", g.Player.CurrentRoom.Name), ColorBold+ColorYellow)
-	fmt.Println()
-	printSeparator()
+// emit records an event as output of the current command
+func (g *Game) emit(ev Event) {
+	g.events = append(g.events, ev)
+}
 
-	printColored(g.Player.CurrentRoom.Description, ColorWhite)
-	fmt.Println()
+// message records a one-line message of the given kind
+func (g *Game) message(kind EventKind, text string) {
+	g.emit(Event{Kind: kind, Text: text})
+}
 
-	// Show player stats
-	fmt.Println()
-	printColored("📊 YOUR STATS:", ColorBold+ColorCyan)
-	fmt.Printf("  💻 Hacking: %d/100    ⚙️ Engineering: %d/100\n", g.Player.Stats.Hacking, g.Player.Stats.Engineering)
-	fmt.Printf("  ⭐ Astronomy: %d/100  🧬 Biology: %d/100\n", g.Player.Stats.Astronomy, g.Player.Stats.Biology)
-	fmt.Printf("  ⚡ Physics: %d/100    🔋 Energy: %d/100\n", g.Player.Stats.Physics, g.Player.Stats.Energy)
-	fmt.Printf("  ⏰ Time Left: %s\n", g.Player.Stats.TimeLeft.Round(time.Second))
+// pause gives the player time to read what was just shown
+func (g *Game) pause(d time.Duration) {
+	g.emit(Event{Kind: EventPause, Delay: d})
+}
 
-	if len(g.Player.CurrentRoom.Items) > 0 {
-		fmt.Println()
-		printColored("🔍 You can see:", ColorGreen)
-		for _, item := range g.Player.CurrentRoom.Items {
-			fmt.Printf("  • ")
-			printColored(item.Name, ColorCyan)
-			fmt.Printf(" - %s\n", item.Description)
-			printASCII(item.ASCII)
+// withPrerequisites returns quest preceded by every prerequisite quest that
+// is not selected yet, prerequisites first.
+func withPrerequisites(quest *Quest, byID map[int]*Quest, selected map[int]bool) []*Quest {
+	var group []*Quest
+	seen := make(map[int]bool)
+	var visit func(q *Quest)
+	visit = func(q *Quest) {
+		if selected[q.ID] || seen[q.ID] {
+			return
+		}
+		seen[q.ID] = true
+		for _, id := range prerequisiteQuests(q) {
+			if prereq, ok := byID[id]; ok {
+				visit(prereq)
+			}
 		}
+		group = append(group, q)
 	}
+	visit(quest)
+	return group
+}
 
-	fmt.Println()
-	printColored("🚪 Exits:", ColorBlue)
-	for direction := range g.Player.CurrentRoom.Exits {
-		fmt.Printf("  • ")
-		printColored(direction, ColorPurple)
-		fmt.Println()
-	}
-	printSeparator()
+// Look displays the current room description and items
+func (g *Game) Look() {
+	g.emit(Event{Kind: EventClear})
+	g.emit(Event{Kind: EventRoom, Room: g.roomView()})
+	g.guide()
 }
 
 // Take adds an item to player's inventory
@@ -512,49 +308,49 @@ func (g *Game) Take(itemName string) {
 		if strings.EqualFold(item.Name, itemName) {
 			g.Player.Inventory = append(g.Player.Inventory, item)
 			g.Player.CurrentRoom.Items = append(g.Player.CurrentRoom.Items[:i], g.Player.CurrentRoom.Items[i+1:]...)
-			printSuccess(fmt.Sprintf("You take the %s.", item.Name))
-			time.Sleep(1 * time.Second)
+			g.chargeTime(g.Config.Clock.TakeCost)
+			g.message(EventSuccess, fmt.Sprintf("You take the %s.", item.Name))
+			g.pause(1 * time.Second)
 			g.Look()
 			return
 		}
 	}
-	printError(fmt.Sprintf("There's no %s here.", itemName))
+	g.message(EventError, fmt.Sprintf("There's no %s here.", itemName))
 }
 
 // Inventory displays player's current inventory
 func (g *Game) Inventory() {
-	clearScreen()
-	printColored("🎒 YOUR INVENTORY", ColorBold+ColorYellow)
-	printSeparator()
-
-	if len(g.Player.Inventory) == 0 {
-		printWarning("Your inventory is empty.")
-		return
-	}
-
-	for _, item := range g.Player.Inventory {
-		fmt.Printf("  • ")
-		printColored(item.Name, ColorCyan)
-		fmt.Printf(" - %s\n", item.Description)
-		printASCII(item.ASCII)
-	}
-	printSeparator()
+	g.emit(Event{Kind: EventClear})
+	g.emit(Event{Kind: EventInventory, Items: itemViews(g.Player.Inventory)})
 }
 
 // Move changes the player's current room
 func (g *Game) Move(direction string) {
-	if room, exists := g.Player.CurrentRoom.Exits[strings.ToLower(direction)]; exists {
+	direction = strings.ToLower(direction)
+	if lock, locked := g.Player.CurrentRoom.Locks[direction]; locked {
+		g.message(EventWarning, fmt.Sprintf("The way %s is locked. Perhaps the %s would open it.", direction, lock))
+		return
+	}
+	if room, exists := g.Player.CurrentRoom.Exits[direction]; exists {
+		if !g.canEnter(room) || !g.airlockOpen(room) || !g.haveEnergy(moveEnergyCost) {
+			return
+		}
+		g.spendEnergy(moveEnergyCost)
+		g.announce(g.Player.CurrentRoom, fmt.Sprintf("%s leaves %s.", g.Player.Name, direction))
 		g.Player.CurrentRoom = room
-		printInfo(fmt.Sprintf("You go %s...", direction))
-		time.Sleep(1 * time.Second)
+		g.announce(room, fmt.Sprintf("%s arrives.", g.Player.Name))
+		g.chargeTime(g.Config.Clock.MoveCost)
+		g.message(EventInfo, fmt.Sprintf("You go %s...", direction))
+		g.pause(1 * time.Second)
 		g.Look()
 	} else {
-		printError(fmt.Sprintf("You can't go %s from here.", direction))
+		g.message(EventError, fmt.Sprintf("You can't go %s from here.", direction))
 	}
 }
 
-// Use attempts to use an item
-func (g *Game) Use(itemName string) {
+// Use attempts to use an item, on target if given: a direction for keys or
+// a quest ID for hint items
+func (g *Game) Use(itemName, target string) {
 	// Check if player has the item
 	var item *Item
 	for _, invItem := range g.Player.Inventory {
@@ -565,220 +361,264 @@ func (g *Game) Use(itemName string) {
 	}
 
 	if item == nil {
-		printError(fmt.Sprintf("You don't have a %s.", itemName))
+		g.message(EventError, fmt.Sprintf("You don't have a %s.", itemName))
 		return
 	}
 
-	// Simple puzzle: using the key in the living room
-	if strings.ToLower(item.Name) == "key" && strings.ToLower(g.Player.CurrentRoom.Name) == "living room" {
-		clearScreen()
-		printInfo("You try the key in the locked door...")
-		time.Sleep(2 * time.Second)
-		clearScreen()
-		printSuccess("🎉 SUCCESS! The door unlocks!")
-		printSuccess("You have escaped the room! Congratulations!")
-		time.Sleep(3 * time.Second)
-		os.Exit(0)
-	} else {
-		printWarning(fmt.Sprintf("You can't use the %s here.", item.Name))
-	}
-}
-
-// Quest methods
-func (g *Game) ShowQuests() {
-	clearScreen()
-	printColored("🎯 YOUR QUESTS", ColorBold+ColorYellow)
-	printSeparator()
-
-	if len(g.Player.Quests) == 0 {
-		printWarning("No active quests!")
-		printInfo("Debug: Player has 0 quests")
+	if !item.Usable {
+		g.message(EventWarning, fmt.Sprintf("The %s is not something you can use.", item.Name))
 		return
 	}
 
-	printInfo(fmt.Sprintf("Debug: Player has %d quests", len(g.Player.Quests)))
-
-	availableQuests := 0
-	for i, quest := range g.Player.Quests {
-		status := "❌"
-		if quest.Solved {
-			status = "✅"
-		} else {
-			availableQuests++
-		}
-
-		emoji := getCategoryEmoji(quest.Category)
-		categoryName := getCategoryName(quest.Category)
-
-		fmt.Printf("%s %s %s (ID: %d) - %s\n", status, emoji, quest.Name, quest.ID, categoryName)
-		fmt.Printf("   Difficulty: %d/5 ⭐\n", quest.Difficulty)
-		fmt.Printf("   Time Limit: %s\n", quest.TimeLimit.Round(time.Second))
-		fmt.Printf("   Reward: %s\n", quest.Reward)
-		fmt.Printf("   Description: %s\n", quest.Description)
-
-		if !quest.Solved {
-			printASCII(quest.ASCII)
-		}
-
-		if i < len(g.Player.Quests)-1 {
-			fmt.Println()
+	// A lock opens with the item it names, even without an unlock effect
+	before := len(g.events)
+	used := false
+	if !item.hasEffect(EffectUnlock) {
+		used = g.unlockWith(item, target)
+	}
+	for _, effect := range item.Effects {
+		if g.applyEffect(item, effect, target) {
+			used = true
 		}
 	}
 
-	if availableQuests > 0 {
-		printInfo(fmt.Sprintf("Available quest IDs: "))
-		for _, quest := range g.Player.Quests {
-			if !quest.Solved {
-				fmt.Printf("%d ", quest.ID)
-			}
-		}
-		fmt.Println()
+	switch {
+	case !used && len(g.events) == before:
+		g.message(EventWarning, fmt.Sprintf("You can't use the %s here.", item.Name))
+	case used && item.Consumable:
+		g.removeItem(item)
+		g.message(EventInfo, fmt.Sprintf("The %s is used up.", item.Name))
 	}
+}
 
-	printSeparator()
-	printInfo("Press Enter to continue...")
-	fmt.Scanln()
-	g.Look()
+// Quest methods
+func (g *Game) ShowQuests() {
+	g.emit(Event{Kind: EventClear})
+	g.emit(Event{Kind: EventQuests, Quests: g.questViews()})
+	g.openMenu()
 }
 
+// StartQuest shows a quest briefing; the next input is taken as the answer
 func (g *Game) StartQuest(questID int) {
-	clearScreen()
-
-	printInfo(fmt.Sprintf("Debug: Looking for quest ID %d", questID))
-	printInfo(fmt.Sprintf("Debug: Player has %d quests", len(g.Player.Quests)))
+	g.emit(Event{Kind: EventClear})
 
+	// In multiplayer a co-op quest of another player can be joined to help
+	candidates := g.Player.Quests
+	if g.world != nil {
+		candidates = g.AllQuests
+	}
 	var quest *Quest
-	for i, q := range g.Player.Quests {
-		printInfo(fmt.Sprintf("Debug: Quest %d: ID=%d, Name=%s, Solved=%t", i, q.ID, q.Name, q.Solved))
-		if q.ID == questID && !q.Solved {
+	for _, q := range candidates {
+		if q.ID == questID && !q.Solved && (g.hasQuest(q) || q.Coop != nil) {
 			quest = q
 			break
 		}
 	}
 
 	if quest == nil {
-		printError("Quest not found or already completed!")
-		printInfo("Available quest IDs:")
+		g.message(EventError, "Quest not found or already completed!")
+		g.message(EventInfo, "Available quest IDs:")
 		availableCount := 0
 		for _, q := range g.Player.Quests {
 			if !q.Solved {
-				fmt.Printf("  - %d: %s\n", q.ID, q.Name)
+				g.message(EventText, fmt.Sprintf("  - %d: %s", q.ID, q.Name))
 				availableCount++
 			}
 		}
 		if availableCount == 0 {
-			printWarning("No available quests! All quests are completed.")
+			g.message(EventWarning, "No available quests! All quests are completed.")
+		}
+		g.pause(3 * time.Second)
+		g.Look()
+		return
+	}
+	if !g.hasQuest(quest) && !g.coopOpen(quest) {
+		g.message(EventWarning, fmt.Sprintf("Nobody is working on %s yet: a player who has the quest must start it before you can help.", quest.Name))
+		g.pause(3 * time.Second)
+		g.Look()
+		return
+	}
+
+	if missing := g.missingRequirements(quest); len(missing) > 0 {
+		g.message(EventError, fmt.Sprintf("🔒 %s is locked:", quest.Name))
+		for _, reason := range missing {
+			g.message(EventText, "  - "+reason)
 		}
-		time.Sleep(3 * time.Second)
+		g.pause(3 * time.Second)
 		g.Look()
 		return
 	}
 
-	printColored(fmt.Sprintf("🎯 STARTING QUEST: %s", quest.Name), ColorBold+ColorYellow)
-	printSeparator()
+	if !g.haveEnergy(g.attemptEnergy(quest)) {
+		g.pause(3 * time.Second)
+		g.Look()
+		return
+	}
 
-	emoji := getCategoryEmoji(quest.Category)
-	categoryName := getCategoryName(quest.Category)
+	var coop *CoopView
+	if quest.Coop != nil && g.world != nil {
+		quest, coop = g.joinCoop(quest)
+	}
 
-	fmt.Printf("%s Category: %s\n", emoji, categoryName)
-	fmt.Printf("⭐ Difficulty: %d/5\n", quest.Difficulty)
-	fmt.Printf("⏰ Time Limit: %s\n", quest.TimeLimit.Round(time.Second))
-	fmt.Printf("🎁 Reward: %s\n", quest.Reward)
-	fmt.Println()
-	printColored("Description:", ColorCyan)
-	fmt.Println(quest.Description)
-	fmt.Println()
+	view := questView(quest)
+	view.Coop = coop
+	view.EnergyCost = g.attemptEnergy(quest)
+	view.TimeLimit = g.timeLimit(quest)
+	g.emit(Event{Kind: EventQuest, Quest: &view})
+	if g.mode().Guided {
+		g.message(EventInfo, "👉 Tutorial: type your answer and press Enter. There is no time limit, and a wrong answer costs nothing.")
+	}
+	g.activeQuest = quest
+	g.attempt = questAttempt{started: g.now()}
+}
 
-	printASCII(quest.ASCII)
+// NextPrompt tells the frontend what kind of input comes next. It holds
+// the world, as other players' commands can change the player's skills
+func (g *Game) NextPrompt() Prompt {
+	defer g.lock()()
+	switch {
+	case g.activeQuest != nil:
+		if !g.mode().EnforceTimeLimits {
+			return Prompt{Mode: PromptAnswer}
+		}
+		return Prompt{Mode: PromptAnswer, QuestTimeLeft: g.questTimeLeft()}
+	case g.menuOpen:
+		return Prompt{Mode: PromptContinue}
+	default:
+		return Prompt{Mode: PromptCommand}
+	}
+}
 
-	fmt.Println()
-	printColored("Enter your solution:", ColorGreen)
-	fmt.Print("> ")
+// addStat raises the skill that belongs to a quest category, up to
+// maxSkill; all of the experience counts towards the player's level
+func (g *Game) addStat(category QuestCategory, amount int) {
+	g.Player.Stats.XP += amount
+	if skill := g.skill(category); skill != nil {
+		*skill += amount
+		if *skill > maxSkill {
+			*skill = maxSkill
+		}
+	}
+}
 
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	solution := strings.TrimSpace(scanner.Text())
+// failQuest ends the active attempt without solving the quest
+func (g *Game) failQuest(reason string) {
+	g.activeQuest = nil
+	g.Player.WrongAttempts++
+	g.message(EventError, reason)
+	g.spendEnergy(g.mode().WrongAnswerEnergy) // Lose energy for wrong answer
+	g.pause(3 * time.Second)
+	g.Look()
+}
 
-	if strings.EqualFold(solution, quest.Solution) {
-		quest.Solved = true
-		g.Player.Completed++
+// answerQuest checks the answer to the active quest
+func (g *Game) answerQuest(solution string) {
+	quest := g.activeQuest
+	solution = strings.TrimSpace(solution)
+	g.chargeTime(g.Config.Clock.AttemptCost)
 
-		// Award experience based on category
-		switch quest.Category {
-		case HackerQuest:
-			g.Player.Stats.Hacking += quest.Difficulty * 5
-		case EngineeringQuest:
-			g.Player.Stats.Engineering += quest.Difficulty * 5
-		case AstronomicalQuest:
-			g.Player.Stats.Astronomy += quest.Difficulty * 5
-		case BiologicalQuest:
-			g.Player.Stats.Biology += quest.Difficulty * 5
-		case PhysicalQuest:
-			g.Player.Stats.Physics += quest.Difficulty * 5
-		}
-
-		// Add reward to inventory
-		rewardItem := &Item{
-			Name:        quest.Reward,
-			Description: fmt.Sprintf("Reward for completing: %s", quest.Name),
-			Usable:      true,
-			ASCII:       fmt.Sprintf("    🎁 %s", quest.Reward),
-			QuestID:     quest.ID,
-		}
-		g.Player.Inventory = append(g.Player.Inventory, rewardItem)
-
-		printSuccess(fmt.Sprintf("🎉 QUEST COMPLETED! You earned: %s", quest.Reward))
-		printSuccess(fmt.Sprintf("Experience gained in %s!", categoryName))
-
-		// Check if all quests completed
-		allCompleted := true
-		for _, q := range g.Player.Quests {
-			if !q.Solved {
-				allCompleted = false
-				break
-			}
-		}
+	if g.mode().EnforceTimeLimits && g.questTimeLeft() <= 0 {
+		g.failQuest("⏰ Too late! The quest's time limit ran out.")
+		return
+	}
+	g.spendEnergy(g.attemptEnergy(quest))
 
-		if allCompleted {
-			printSuccess("🏆 CONGRATULATIONS! You completed all quests!")
-			printSuccess("You have successfully escaped the Cosmic Cyberpunk Room!")
-			time.Sleep(5 * time.Second)
-			os.Exit(0)
+	if quest.Coop != nil && g.world != nil {
+		g.answerCoop(solution)
+		return
+	}
+
+	if quest.checkAnswer(solution) {
+		g.activeQuest = nil
+		// Answering well under the limit earns extra experience
+		g.completeQuest(quest, g.now().Sub(g.attempt.started) <= g.timeLimit(quest)/speedBonusFraction)
+		if g.outcome == OutcomeWon {
+			return
 		}
 	} else {
-		printError("❌ Incorrect solution! Try again.")
-		g.Player.Stats.Energy -= 10 // Lose energy for wrong answer
-		if g.Player.Stats.Energy < 0 {
-			g.Player.Stats.Energy = 0
-		}
+		g.failQuest("❌ Incorrect solution! Try again.")
+		return
 	}
 
-	time.Sleep(3 * time.Second)
+	g.pause(3 * time.Second)
 	g.Look()
 }
 
+// hasQuest reports whether quest is one of the player's own quests.
+func (g *Game) hasQuest(quest *Quest) bool {
+	for _, q := range g.Player.Quests {
+		if q == quest {
+			return true
+		}
+	}
+	return false
+}
+
+// completeQuest marks a quest solved and hands out its rewards; fast earns
+// the speed bonus. Solving the last of the player's quests wins the game.
+func (g *Game) completeQuest(quest *Quest, fast bool) {
+	quest.Solved = true
+	if g.hasQuest(quest) {
+		g.Player.Completed++
+	}
+
+	// Award experience based on category, less for every hint used
+	// beyond the free ones
+	level, _ := levelFor(g.Player.Stats.XP)
+	hints := g.Player.HintsUsed[quest.ID]
+	paid := hints - g.freeHints(quest)
+	if paid < 0 {
+		paid = 0
+	}
+	xp := quest.Difficulty*5 - quest.Difficulty*g.mode().HintXPPenalty*paid
+	if xp < 0 {
+		xp = 0
+	}
+	g.addStat(quest.Category, xp)
+
+	// Add reward to inventory
+	g.Player.Inventory = append(g.Player.Inventory, g.rewardItem(quest))
+
+	g.message(EventSuccess, fmt.Sprintf("🎉 QUEST COMPLETED! You earned: %s", quest.Reward))
+	if hints > 0 {
+		g.message(EventSuccess, fmt.Sprintf("Experience gained in %s: +%d (%d hints used)", getCategoryName(quest.Category), xp, hints))
+	} else {
+		g.message(EventSuccess, fmt.Sprintf("Experience gained in %s!", getCategoryName(quest.Category)))
+	}
+
+	if fast {
+		g.addStat(quest.Category, quest.Difficulty*speedBonusPerDifficulty)
+		g.message(EventSuccess, fmt.Sprintf("⚡ Speed bonus! Extra experience in %s!", getCategoryName(quest.Category)))
+	}
+	if newLevel, r := levelFor(g.Player.Stats.XP); newLevel > level {
+		g.message(EventSuccess, fmt.Sprintf("⬆️ Level up! You are now level %d: %s.", newLevel, r.Title))
+	}
+
+	g.checkRoomSolved(quest)
+
+	// Check if all quests completed
+	allCompleted := g.questsSolved()
+
+	switch e := g.escape(); {
+	case allCompleted && e != nil:
+		g.message(EventSuccess, "🏆 CONGRATULATIONS! You completed all quests!")
+		g.message(EventInfo, fmt.Sprintf("Head for the %s and type 'escape' to get out.", g.Rooms[e.Room].Name))
+	case allCompleted:
+		g.message(EventSuccess, "🏆 CONGRATULATIONS! You completed all quests!")
+		g.message(EventSuccess, "You have successfully escaped the Cosmic Cyberpunk Room!")
+		g.finish(OutcomeWon)
+	}
+}
+
 func (g *Game) ShowStats() {
-	clearScreen()
-	printColored("📊 DETAILED STATS", ColorBold+ColorYellow)
-	printSeparator()
-
-	fmt.Printf("💻 Hacking: %d/100\n", g.Player.Stats.Hacking)
-	fmt.Printf("⚙️ Engineering: %d/100\n", g.Player.Stats.Engineering)
-	fmt.Printf("⭐ Astronomy: %d/100\n", g.Player.Stats.Astronomy)
-	fmt.Printf("🧬 Biology: %d/100\n", g.Player.Stats.Biology)
-	fmt.Printf("⚡ Physics: %d/100\n", g.Player.Stats.Physics)
-	fmt.Printf("🔋 Energy: %d/100\n", g.Player.Stats.Energy)
-	fmt.Printf("⏰ Time Left: %s\n", g.Player.Stats.TimeLeft.Round(time.Second))
-	fmt.Printf("✅ Quests Completed: %d/%d\n", g.Player.Completed, len(g.Player.Quests))
-
-	printSeparator()
-	printInfo("Press Enter to continue...")
-	fmt.Scanln()
-	g.Look()
+	stats := g.statsView()
+	g.emit(Event{Kind: EventClear})
+	g.emit(Event{Kind: EventStats, Stats: &stats})
+	g.openMenu()
 }
 
 func (g *Game) ShowHints(questID int) {
-	clearScreen()
+	g.emit(Event{Kind: EventClear})
 
 	var quest *Quest
 	for _, q := range g.Player.Quests {
@@ -789,72 +629,219 @@ func (g *Game) ShowHints(questID int) {
 	}
 
 	if quest == nil {
-		printError("Quest not found!")
-		time.Sleep(2 * time.Second)
+		g.message(EventError, "Quest not found!")
+		g.pause(2 * time.Second)
 		g.Look()
 		return
 	}
 
-	printColored(fmt.Sprintf("💡 HINTS FOR: %s", quest.Name), ColorBold+ColorYellow)
-	printSeparator()
+	if !g.mode().Hints {
+		g.message(EventWarning, fmt.Sprintf("There are no hints in %s mode.", g.GameMode))
+		return
+	}
+	g.showHints(quest, true)
+}
 
-	emoji := getCategoryEmoji(quest.Category)
-	categoryName := getCategoryName(quest.Category)
+// showHints shows the hints of quest revealed so far and, with prompt, how
+// to reveal the next one.
+func (g *Game) showHints(quest *Quest, prompt bool) {
+	// Only the hints revealed so far are shown
+	used := g.Player.HintsUsed[quest.ID]
+	view := questView(quest)
+	view.HintsUsed = used
+	view.Hints = quest.Hints[:used]
+	if used > 0 {
+		view.Example = quest.Example
+	}
+	g.emit(Event{Kind: EventHints, Quest: &view})
+	if prompt && used < len(quest.Hints) && !quest.Solved {
+		cost := fmt.Sprintf("costs %d energy", g.hintEnergy(quest))
+		switch {
+		case used < g.freeHints(quest):
+			cost = "free thanks to your skill"
+		case g.hintEnergy(quest) == 0:
+			cost = "free in " + g.GameMode + " mode"
+		}
+		g.message(EventInfo, fmt.Sprintf("Type 'hint %d' to reveal the next hint (%s).", quest.ID, cost))
+	}
+	g.openMenu()
+}
 
-	fmt.Printf("%s Category: %s\n", emoji, categoryName)
-	fmt.Printf("⭐ Difficulty: %d/5\n", quest.Difficulty)
-	fmt.Println()
+// In normal mode every revealed hint costs energy and lowers the
+// experience the quest awards by hintXPPenalty points per difficulty level.
+const (
+	hintEnergyCost = 5
+	hintXPPenalty  = 1
+)
 
-	printColored("Description:", ColorCyan)
-	fmt.Println(quest.Description)
-	fmt.Println()
+// RevealHint reveals the next hint of a quest. The last hint gives the
+// answer away, so it is only shown when confirm is set.
+func (g *Game) RevealHint(questID int, confirm bool) {
+	quest := g.hintQuest(questID, confirm)
+	if quest == nil {
+		return
+	}
 
-	printASCII(quest.ASCII)
-	fmt.Println()
+	used := g.Player.HintsUsed[quest.ID]
+	free := used < g.freeHints(quest)
+	cost := g.hintEnergy(quest)
+	if !g.haveEnergy(cost) {
+		return
+	}
+	g.spendEnergy(cost)
+	g.Player.HintsUsed[quest.ID] = used + 1
+
+	g.ShowHints(quest.ID)
+	switch {
+	case free:
+		g.message(EventInfo, "Hint revealed, free thanks to your skill.")
+	case g.mode().HintXPPenalty == 0 && cost == 0:
+		g.message(EventInfo, fmt.Sprintf("Hint revealed, free in %s mode.", g.GameMode))
+	default:
+		g.message(EventWarning, fmt.Sprintf("Hint revealed: -%d energy, less experience for this quest.", cost))
+	}
+}
 
-	printColored("💡 HINTS:", ColorGreen)
-	for i, hint := range quest.Hints {
-		fmt.Printf("%s\n", hint)
-		if i < len(quest.Hints)-1 {
-			fmt.Println()
+// hintQuest returns the player's quest questID if its next hint may be
+// revealed, and explains why not otherwise. The last hint gives the answer
+// away, so it may only be revealed when confirm is set.
+func (g *Game) hintQuest(questID int, confirm bool) *Quest {
+	var quest *Quest
+	for _, q := range g.Player.Quests {
+		if q.ID == questID {
+			quest = q
+			break
 		}
 	}
 
-	fmt.Println()
-	printColored("Example:", ColorCyan)
-	fmt.Println(quest.Example)
+	switch {
+	case quest == nil:
+		g.message(EventError, "Quest not found!")
+		return nil
+	case quest.Solved:
+		g.message(EventWarning, "You have already completed this quest.")
+		return nil
+	case !g.mode().Hints:
+		g.message(EventWarning, fmt.Sprintf("There are no hints in %s mode.", g.GameMode))
+		return nil
+	case len(quest.Hints) == 0:
+		g.message(EventWarning, "This quest has no hints.")
+		return nil
+	}
 
-	printSeparator()
-	printInfo("Press Enter to continue...")
-	fmt.Scanln()
-	g.Look()
+	used := g.Player.HintsUsed[quest.ID]
+	if used >= len(quest.Hints) {
+		g.message(EventWarning, "You have revealed every hint for this quest.")
+		return nil
+	}
+	if used == len(quest.Hints)-1 && !confirm {
+		g.message(EventWarning, "The last hint reveals the answer!")
+		g.message(EventInfo, fmt.Sprintf("Type 'hint %d reveal' if you really want to see it.", quest.ID))
+		return nil
+	}
+	return quest
+}
+
+// hintEnergy returns what the next hint of quest costs.
+func (g *Game) hintEnergy(quest *Quest) int {
+	if g.mode().UnlimitedEnergy || g.Player.HintsUsed[quest.ID] < g.freeHints(quest) {
+		return 0
+	}
+	return g.mode().HintEnergy
+}
+
+// commands lists every command for the help screen
+var commands = []CommandHelp{
+	{"look/l", "Look around the current room"},
+	{"take <item>", "Pick up an item"},
+	{"inventory/i", "Check your inventory"},
+	{"use <item> [on <target>]", "Use an item, on a direction or quest ID"},
+	{"go <direction>", "Move in a direction"},
+	{"quests/q", "Show your quests"},
+	{"start <quest_id>", "Start a quest"},
+	{"hint <quest_id>", "Reveal the next hint for a quest (costs energy)"},
+	{"hints <quest_id>", "Show the hints revealed for a quest"},
+	{"stats/s", "Show detailed stats"},
+	{"rest [minutes]", "Trade time for energy (default 5 minutes)"},
+	{"escape [code]", "Show the airlock puzzle, or enter its code"},
+	{"leaderboard [mode] [seed]", "Show the best local runs"},
+	{"say <message>", "Talk to the players in the room"},
+	{"whisper <player> <message>", "Talk to one player"},
+	{"save <slot>", "Save the game to a slot"},
+	{"load <slot>", "Load the game from a slot"},
+	{"help/h", "Show this help"},
+	{"quit/exit", "Exit the game"},
 }
 
 // Help displays available commands
 func (g *Game) Help() {
-	clearScreen()
-	printColored("❓ GAME HELP", ColorBold+ColorYellow)
-	printSeparator()
-	fmt.Println("Available commands:")
-	fmt.Printf("  %s - Look around the current room\n", ColorCyan+"look/l"+ColorReset)
-	fmt.Printf("  %s - Pick up an item\n", ColorCyan+"take <item>"+ColorReset)
-	fmt.Printf("  %s - Check your inventory\n", ColorCyan+"inventory/i"+ColorReset)
-	fmt.Printf("  %s - Use an item\n", ColorCyan+"use <item>"+ColorReset)
-	fmt.Printf("  %s - Move in a direction\n", ColorCyan+"go <direction>"+ColorReset)
-	fmt.Printf("  %s - Show your quests\n", ColorCyan+"quests/q"+ColorReset)
-	fmt.Printf("  %s - Start a quest\n", ColorCyan+"start <quest_id>"+ColorReset)
-	fmt.Printf("  %s - Show hints for a quest\n", ColorCyan+"hints <quest_id>"+ColorReset)
-	fmt.Printf("  %s - Show detailed stats\n", ColorCyan+"stats/s"+ColorReset)
-	fmt.Printf("  %s - Show this help\n", ColorCyan+"help/h"+ColorReset)
-	fmt.Printf("  %s - Exit the game\n", ColorCyan+"quit/q"+ColorReset)
-	printSeparator()
-	printInfo("Press Enter to continue...")
-	fmt.Scanln()
-	g.Look()
+	g.emit(Event{Kind: EventClear})
+	g.emit(Event{Kind: EventHelp, Commands: commands})
+	g.openMenu()
 }
 
-// ProcessCommand handles user input
-func (g *Game) ProcessCommand(input string) {
+// Quit ends the game at the player's request
+func (g *Game) Quit() {
+	g.emit(Event{Kind: EventClear})
+	g.message(EventSuccess, "Thanks for playing! Goodbye!")
+	g.pause(2 * time.Second)
+	g.outcome = OutcomeQuit
+}
+
+// checkEnd ends the game once time or energy has run out
+func (g *Game) checkEnd() {
+	if g.outcome != OutcomeContinue {
+		return
+	}
+
+	// Check if time is up
+	if g.Player.Stats.TimeLeft <= 0 {
+		g.emit(Event{Kind: EventClear})
+		g.message(EventError, "⏰ TIME'S UP! You failed to escape in time!")
+		g.message(EventError, "The facility's security systems have locked you in permanently!")
+		g.finish(OutcomeLost)
+		return
+	}
+
+	// Running out of energy is only fatal in modes with exhaustion;
+	// otherwise the player has to rest
+	if g.Player.Stats.Energy <= 0 && g.mode().Exhaustion {
+		g.emit(Event{Kind: EventClear})
+		g.message(EventError, "🔋 ENERGY DEPLETED! You collapsed from exhaustion!")
+		g.message(EventError, "Nobody comes to wake you up...")
+		g.finish(OutcomeLost)
+	}
+}
+
+// ProcessCommand handles user input and returns everything it produced
+func (g *Game) ProcessCommand(input string) Result {
+	defer g.lock()()
+	g.events = nil
+	g.beginStep()
+
+	// Any input closes an open menu; a bare Enter just returns to the room
+	closedMenu := g.menuOpen
+	if closedMenu {
+		g.closeMenu()
+	}
+	g.syncClock()
+
+	switch {
+	case g.activeQuest != nil:
+		g.answerQuest(input)
+	case closedMenu && strings.TrimSpace(input) == "":
+		g.Look()
+	default:
+		g.dispatch(input)
+	}
+
+	g.syncClock()
+	g.checkEnd()
+	return Result{Events: g.events, Outcome: g.outcome}
+}
+
+// dispatch runs a single command
+func (g *Game) dispatch(input string) {
 	parts := strings.Fields(strings.ToLower(input))
 	if len(parts) == 0 {
 		return
@@ -870,23 +857,27 @@ func (g *Game) ProcessCommand(input string) {
 			itemName := strings.Join(parts[1:], " ")
 			g.Take(itemName)
 		} else {
-			fmt.Println("Take what?")
+			g.message(EventText, "Take what?")
 		}
 	case "inventory", "i":
 		g.Inventory()
 	case "use":
 		if len(parts) > 1 {
-			itemName := strings.Join(parts[1:], " ")
-			g.Use(itemName)
+			// "use <item> on <target>"
+			itemName, target := strings.Join(parts[1:], " "), ""
+			if i := strings.Index(itemName, " on "); i >= 0 {
+				itemName, target = itemName[:i], strings.TrimSpace(itemName[i+4:])
+			}
+			g.Use(itemName, target)
 		} else {
-			fmt.Println("Use what?")
+			g.message(EventText, "Use what?")
 		}
 	case "go":
 		if len(parts) > 1 {
 			direction := strings.Join(parts[1:], " ")
 			g.Move(direction)
 		} else {
-			fmt.Println("Go where?")
+			g.message(EventText, "Go where?")
 		}
 	case "quests", "q":
 		g.ShowQuests()
@@ -895,81 +886,426 @@ func (g *Game) ProcessCommand(input string) {
 			if questID, err := strconv.Atoi(parts[1]); err == nil {
 				g.StartQuest(questID)
 			} else {
-				printError("Invalid quest ID. Use a number.")
+				g.message(EventError, "Invalid quest ID. Use a number.")
 			}
 		} else {
-			fmt.Println("Start which quest? Use quest ID number.")
+			g.message(EventText, "Start which quest? Use quest ID number.")
 		}
 	case "hints":
 		if len(parts) > 1 {
 			if questID, err := strconv.Atoi(parts[1]); err == nil {
 				g.ShowHints(questID)
 			} else {
-				printError("Invalid quest ID. Use a number.")
+				g.message(EventError, "Invalid quest ID. Use a number.")
+			}
+		} else {
+			g.message(EventText, "Show hints for which quest? Use quest ID number.")
+		}
+	case "hint":
+		if len(parts) > 1 {
+			if questID, err := strconv.Atoi(parts[1]); err == nil {
+				g.RevealHint(questID, len(parts) > 2 && parts[2] == "reveal")
+			} else {
+				g.message(EventError, "Invalid quest ID. Use a number.")
 			}
 		} else {
-			fmt.Println("Show hints for which quest? Use quest ID number.")
+			g.message(EventText, "Reveal a hint for which quest? Use quest ID number.")
 		}
 	case "stats", "s":
 		g.ShowStats()
+	case "rest":
+		d := defaultRest
+		if len(parts) > 1 {
+			minutes, err := strconv.Atoi(parts[1])
+			if err != nil {
+				g.message(EventError, "Rest for how long? Use a number of minutes.")
+				return
+			}
+			d = time.Duration(minutes) * time.Minute
+		}
+		g.Rest(d)
+	case "escape":
+		g.Escape(strings.Join(parts[1:], " "))
+	case "leaderboard", "top":
+		g.ShowLeaderboard(parts[1:])
+	case "save":
+		if len(parts) > 1 {
+			if err := g.Save(parts[1]); err != nil {
+				g.message(EventError, fmt.Sprintf("Could not save: %v", err))
+			} else {
+				g.message(EventSuccess, fmt.Sprintf("Game saved to slot '%s'.", parts[1]))
+			}
+		} else {
+			g.message(EventText, "Save to which slot?")
+		}
+	case "load":
+		if len(parts) > 1 {
+			if err := g.Load(parts[1]); err != nil {
+				g.message(EventError, fmt.Sprintf("Could not load: %v", err))
+			} else {
+				g.message(EventSuccess, fmt.Sprintf("Game loaded from slot '%s'.", parts[1]))
+				g.pause(1 * time.Second)
+				g.Look()
+			}
+		} else {
+			g.message(EventText, "Load which slot?")
+		}
+	case "say":
+		if words := strings.Fields(input); len(words) > 1 {
+			g.Say(strings.Join(words[1:], " "))
+		} else {
+			g.message(EventText, "Say what?")
+		}
+	case "whisper":
+		if words := strings.Fields(input); len(words) > 2 {
+			g.Whisper(words[1], strings.Join(words[2:], " "))
+		} else {
+			g.message(EventText, "Whisper what to whom? Use: whisper <player> <message>")
+		}
 	case "help", "h":
 		g.Help()
 	case "quit", "exit":
-		clearScreen()
-		printSuccess("Thanks for playing! Goodbye!")
-		time.Sleep(2 * time.Second)
-		os.Exit(0)
+		g.Quit()
 	default:
-		printError("I don't understand that command. Type 'help' for available commands.")
+		g.message(EventError, "I don't understand that command. Type 'help' for available commands.")
+	}
+}
+
+// play runs the game loop, reading commands from in and drawing with r,
+// until the game ends or input runs out. The clock ticks every second so
+// time limits expire even while the player is thinking. Every step is
+// written to rec, if given.
+func play(g *Game, lines <-chan string, r Renderer, rec *Recorder) Outcome {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	r.Render(g.ProcessCommand("look").Events)
+	rec.Record(g, "look", false)
+	prompt := g.NextPrompt()
+	r.Prompt(prompt)
+
+	for {
+		var result Result
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return OutcomeQuit
+			}
+			command := strings.TrimSpace(line)
+			if command == "" && prompt.Mode == PromptCommand {
+				r.Prompt(prompt)
+				continue
+			}
+			result = g.ProcessCommand(command)
+			rec.Record(g, command, false)
+		case <-ticker.C:
+			result = g.Tick()
+			if len(result.Events) == 0 && result.Outcome == OutcomeContinue {
+				continue
+			}
+			rec.Record(g, "", true)
+		case events := <-g.inbox:
+			// Other players' messages go below the prompt, which is
+			// shown again afterwards
+			r.Render(append([]Event{{Kind: EventText}}, events...))
+			r.Prompt(prompt)
+			continue
+		}
+
+		r.Render(result.Events)
+		if result.Outcome != OutcomeContinue {
+			return result.Outcome
+		}
+		prompt = g.NextPrompt()
+		r.Prompt(prompt)
+	}
+}
+
+// Exit codes of the game process. Scripts can tell from them how the game
+// ended.
+const (
+	exitWon   = 0
+	exitError = 1
+	exitQuit  = 2
+	exitLost  = 3
+)
+
+func exitCode(outcome Outcome) int {
+	switch outcome {
+	case OutcomeWon:
+		return exitWon
+	case OutcomeLost:
+		return exitLost
+	default:
+		return exitQuit
 	}
 }
 
 func main() {
-	clearScreen()
-	printBanner()
-
-	fmt.Println()
-	printInfo("🌌 Welcome to the Cosmic Cyberpunk Room Escape!")
-	printInfo("You are trapped in a high-tech facility filled with quantum puzzles and cybernetic challenges.")
-	printInfo("Complete quests to gain experience and escape!")
-	fmt.Println()
-	printInfo("Type 'help' for commands or 'quit' to exit.")
-	fmt.Println()
-	printInfo("Press Enter to start your cyberpunk adventure...")
-	fmt.Scanln()
-
-	game := NewGame()
+	questDir := flag.String("quests", "", "directory with extra quest files (*.json)")
+	levelFile := flag.String("level", "", "level file describing the world (default: built-in level)")
+	pauseMenus := flag.Bool("pause-menus", true, "stop the game clock while menus are open")
+	seed := flag.Int64("seed", 0, "seed for quest selection and puzzles, to replay the same game (default: random)")
+	record := flag.String("record", "", "write a transcript of the game to this file")
+	replay := flag.String("replay", "", "re-run a recorded transcript and verify every step, without playing")
+	headless := flag.Bool("headless", false, "no colours, screen clearing or pauses; for scripts and tests")
+	format := flag.String("format", "text", "output format in headless mode: text or json")
+	protocol := flag.Bool("protocol", false, "read JSON commands from stdin and answer each with the game state as JSON")
+	mode := flag.String("mode", defaultMode, "game mode: "+modeNames())
+	addr := flag.String("addr", ":4000", "address the multiplayer or HTTP server listens on (serve and api modes)")
+	sessionTTL := flag.Duration("session-ttl", 30*time.Minute, "how long an unused HTTP session lives (api mode)")
+	maxSessions := flag.Int("max-sessions", 1000, "most HTTP sessions live at once, 0 for no limit (api mode)")
+	name := flag.String("name", defaultPlayerName(), "your name on the leaderboard")
+	leaderboard := flag.Bool("leaderboard", false, "print the best local runs and exit; --mode and --seed filter them")
+
+	// "serve" runs a multiplayer server and "api" an HTTP server instead
+	// of a local game
+	serve := len(os.Args) > 1 && os.Args[1] == "serve"
+	api := len(os.Args) > 1 && os.Args[1] == "api"
+	if serve || api {
+		flag.CommandLine.Parse(os.Args[2:])
+	} else {
+		flag.Parse()
+	}
+
+	if *replay != "" {
+		if _, err := Replay(*replay, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "Replay failed:", err)
+			os.Exit(exitError)
+		}
+		os.Exit(0)
+	}
+
+	if _, ok := findMode(*mode); !ok {
+		fmt.Fprintf(os.Stderr, "Unknown mode %q: use %s\n", *mode, modeNames())
+		os.Exit(exitError)
+	}
+
 	scanner := bufio.NewScanner(os.Stdin)
+	var term Renderer = NewTerminalRenderer(os.Stdout)
+	if *headless || *protocol {
+		switch *format {
+		case "text":
+			term = NewPlainRenderer(os.Stdout)
+		case "json":
+			term = NewJSONRenderer(os.Stdout)
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown output format %q: use text or json\n", *format)
+			os.Exit(exitError)
+		}
+	}
 
-	game.Look()
+	if *leaderboard {
+		// Only the filters given on the command line apply
+		var f leaderboardFilter
+		flag.Visit(func(fl *flag.Flag) {
+			switch fl.Name {
+			case "mode":
+				f.Mode = *mode
+			case "seed":
+				f.Seed = *seed
+			}
+		})
+		ev, err := leaderboardEvent(leaderboardFile, f)
+		if err != nil {
+			term.Render([]Event{{Kind: EventError, Text: fmt.Sprintf("Could not read the leaderboard: %v", err)}})
+			os.Exit(exitError)
+		}
+		term.Render([]Event{ev})
+		os.Exit(0)
+	}
 
-	for {
-		// Check if time is up
-		if game.Player.Stats.TimeLeft <= 0 {
-			clearScreen()
-			printError("⏰ TIME'S UP! You failed to escape in time!")
-			printError("The facility's security systems have locked you in permanently!")
-			time.Sleep(3 * time.Second)
-			os.Exit(0)
-		}
-
-		// Check if energy is depleted
-		if game.Player.Stats.Energy <= 0 {
-			clearScreen()
-			printError("🔋 ENERGY DEPLETED! You collapsed from exhaustion!")
-			printError("You need to rest to regain energy!")
-			time.Sleep(3 * time.Second)
-			os.Exit(0)
-		}
-
-		fmt.Print("\n🎮 > ")
-		if !scanner.Scan() {
+	level, err := LoadLevel(*levelFile)
+	if err != nil {
+		term.Render([]Event{{Kind: EventError, Text: "Failed to load level:"}, {Kind: EventText, Text: err.Error()}})
+		os.Exit(exitError)
+	}
+
+	allQuests, err := LoadQuests(*questDir)
+	if err == nil {
+		err = ValidateRequirements(level, allQuests)
+	}
+	if err != nil {
+		term.Render([]Event{{Kind: EventError, Text: "Failed to load quests:"}, {Kind: EventText, Text: err.Error()}})
+		os.Exit(exitError)
+	}
+
+	if serve {
+		clock := DefaultClockConfig()
+		clock.PauseInMenus = *pauseMenus
+		world := NewWorld(GameConfig{Level: level, Quests: allQuests, Clock: clock, Seed: *seed, Mode: *mode})
+		ln, err := net.Listen("tcp", *addr)
+		if err != nil {
+			term.Render([]Event{{Kind: EventError, Text: fmt.Sprintf("Could not listen: %v", err)}})
+			os.Exit(exitError)
+		}
+		term.Render([]Event{{Kind: EventInfo, Text: fmt.Sprintf("🌐 Multiplayer server listening on %s (connect with telnet or nc)", ln.Addr())}})
+		if err := world.Serve(ln); err != nil {
+			term.Render([]Event{{Kind: EventError, Text: err.Error()}})
+			os.Exit(exitError)
+		}
+		return
+	}
+
+	if api {
+		clock := DefaultClockConfig()
+		clock.PauseInMenus = *pauseMenus
+		handler := NewAPI(GameConfig{Level: level, Quests: allQuests, Clock: clock}, *sessionTTL, *maxSessions)
+		ln, err := net.Listen("tcp", *addr)
+		if err != nil {
+			term.Render([]Event{{Kind: EventError, Text: fmt.Sprintf("Could not listen: %v", err)}})
+			os.Exit(exitError)
+		}
+		term.Render([]Event{{Kind: EventInfo, Text: fmt.Sprintf("🌐 HTTP API listening on http://%s/sessions", ln.Addr())}})
+		// Timeouts keep slow or idle clients from holding connections open
+		srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second, IdleTimeout: 2 * time.Minute}
+		if err := srv.Serve(ln); err != nil {
+			term.Render([]Event{{Kind: EventError, Text: err.Error()}})
+			os.Exit(exitError)
+		}
+		return
+	}
+
+	// A script starts playing right away
+	if !*headless && !*protocol {
+		showIntro(term, scanner)
+	}
+
+	clock := DefaultClockConfig()
+	clock.PauseInMenus = *pauseMenus
+	if *record != "" {
+		// Transcripts store wall-clock times, so the game must not use the
+		// monotonic clock or a replay could drift from the recording
+		clock.Now = func() time.Time { return time.Now().Round(0) }
+	}
+
+	game := NewGame(GameConfig{Level: level, Quests: allQuests, Clock: clock, Seed: *seed, Mode: *mode, Name: *name, Leaderboard: leaderboardFile})
+
+	var rec *Recorder
+	if *record != "" {
+		if rec, err = NewRecorder(*record, game, *levelFile, *questDir); err != nil {
+			term.Render([]Event{{Kind: EventError, Text: fmt.Sprintf("Could not record: %v", err)}})
+			os.Exit(exitError)
+		}
+	}
+	if *protocol {
+		outcome := serveProtocol(game, os.Stdin, os.Stdout, rec)
+		if err := rec.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Transcript incomplete: %v\n", err)
+		}
+		os.Exit(exitCode(outcome))
+	}
+
+	lines := readLines(scanner, nil)
+	outcome := play(game, lines, term, rec)
+	if err := rec.Close(); err != nil {
+		term.Render([]Event{{Kind: EventError, Text: fmt.Sprintf("Transcript incomplete: %v", err)}})
+	}
+
+	// At the terminal a finished game leads back to the main menu; only
+	// scripts get the exit code right away. Later games are not recorded.
+	for !*headless {
+		cfg := game.Config
+		cfg.Seed = 0
+		if game = mainMenu(term, lines, cfg); game == nil {
 			break
 		}
+		outcome = play(game, lines, term, nil)
+	}
+	os.Exit(exitCode(outcome))
+}
+
+// defaultPlayerName is the name of the user running the game, if known.
+func defaultPlayerName() string {
+	for _, key := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(key); name != "" {
+			return name
+		}
+	}
+	return "Player"
+}
+
+// readLines passes the lines read from in to the returned channel, which is
+// closed at the end of the input or once done is closed.
+func readLines(in *bufio.Scanner, done <-chan struct{}) <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		for in.Scan() {
+			select {
+			case lines <- in.Text():
+			case <-done:
+				return
+			}
+		}
+	}()
+	return lines
+}
+
+// mainMenu lets the player start a new game from cfg or load a saved one.
+// It returns nil when the player leaves.
+func mainMenu(term Renderer, lines <-chan string, cfg GameConfig) *Game {
+	term.Render([]Event{
+		{Kind: EventText},
+		{Kind: EventInfo, Text: "🏠 MAIN MENU"},
+		{Kind: EventText, Text: fmt.Sprintf("  new [mode]   - start a new game (%s; default %s)", modeNames(), cfg.Mode)},
+		{Kind: EventText, Text: "  load <slot>  - continue a saved game"},
+		{Kind: EventText, Text: "  quit         - leave the game"},
+	})
+	for {
+		term.Prompt(Prompt{Mode: PromptCommand})
+		line, ok := <-lines
+		if !ok {
+			return nil
+		}
+		parts := strings.Fields(strings.ToLower(line))
+		if len(parts) == 0 {
+			continue
+		}
 
-		command := strings.TrimSpace(scanner.Text())
-		if command != "" {
-			game.ProcessCommand(command)
+		switch parts[0] {
+		case "new", "n":
+			if len(parts) > 1 {
+				if _, ok := findMode(parts[1]); !ok {
+					term.Render([]Event{{Kind: EventError, Text: fmt.Sprintf("Unknown mode %q: use %s.", parts[1], modeNames())}})
+					continue
+				}
+				cfg.Mode = parts[1]
+			}
+			return NewGame(cfg)
+		case "load":
+			if len(parts) < 2 {
+				term.Render([]Event{{Kind: EventText, Text: "Load which slot?"}})
+				continue
+			}
+			g := NewGame(cfg)
+			if err := g.Load(parts[1]); err != nil {
+				term.Render([]Event{{Kind: EventError, Text: fmt.Sprintf("Could not load: %v", err)}})
+				continue
+			}
+			return g
+		case "quit", "exit", "q":
+			term.Render([]Event{{Kind: EventSuccess, Text: "Thanks for playing! Goodbye!"}})
+			return nil
+		default:
+			term.Render([]Event{{Kind: EventError, Text: "Type 'new', 'load <slot>' or 'quit'."}})
 		}
 	}
 }
+
+// showIntro greets the player and waits for Enter.
+func showIntro(term Renderer, in *bufio.Scanner) {
+	term.Render([]Event{
+		{Kind: EventClear},
+		{Kind: EventBanner},
+		{Kind: EventText},
+		{Kind: EventInfo, Text: "🌌 Welcome to the Cosmic Cyberpunk Room Escape!"},
+		{Kind: EventInfo, Text: "You are trapped in a high-tech facility filled with quantum puzzles and cybernetic challenges."},
+		{Kind: EventInfo, Text: "Complete quests to gain experience and escape!"},
+		{Kind: EventText},
+		{Kind: EventInfo, Text: "Type 'help' for commands or 'quit' to exit."},
+		{Kind: EventText},
+		{Kind: EventInfo, Text: "Press Enter to start your cyberpunk adventure..."},
+	})
+	in.Scan()
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testClock is a game clock that only moves when a test moves it.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func (c *testClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// testConfig describes a game of the built-in level and quests on a test
+// clock, saving to a temporary directory.
+func testConfig(t *testing.T, mode string, seed int64) (GameConfig, *testClock) {
+	t.Helper()
+	level, err := LoadLevel("")
+	if err != nil {
+		t.Fatalf("LoadLevel: %v", err)
+	}
+	quests, err := LoadQuests("")
+	if err != nil {
+		t.Fatalf("LoadQuests: %v", err)
+	}
+
+	tc := &testClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	clock := DefaultClockConfig()
+	clock.Now = tc.Now
+	return GameConfig{Level: level, Quests: quests, Clock: clock, Seed: seed, Mode: mode, Name: "tester", SaveDir: t.TempDir()}, tc
+}
+
+// newTestGame starts a single player game for a test.
+func newTestGame(t *testing.T, mode string, seed int64) (*Game, *testClock) {
+	t.Helper()
+	cfg, tc := testConfig(t, mode, seed)
+	return NewGame(cfg), tc
+}
+
+// run plays commands one after another and returns the text of every
+// event they produced.
+func run(g *Game, commands ...string) string {
+	var out []string
+	for _, command := range commands {
+		if text := eventText(g.ProcessCommand(command)); text != "" {
+			out = append(out, text)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// eventText returns the text of every event of res, one per line.
+func eventText(res Result) string {
+	var out []string
+	for _, ev := range res.Events {
+		if ev.Text != "" {
+			out = append(out, ev.Text)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// questByID returns the quest of g with id, failing the test if there is
+// none.
+func questByID(t *testing.T, g *Game, id int) *Quest {
+	t.Helper()
+	for _, quest := range g.AllQuests {
+		if quest.ID == id {
+			return quest
+		}
+	}
+	t.Fatalf("no quest %d", id)
+	return nil
+}
+
+// lines returns a closed channel holding commands, as read from a script.
+func lines(commands ...string) <-chan string {
+	ch := make(chan string, len(commands))
+	for _, command := range commands {
+		ch <- command
+	}
+	close(ch)
+	return ch
+}
+
+func TestPlayHeadless(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+	}{
+		{"quit", []string{"look", "", "quit", "look"}},
+		{"end of input", []string{"look"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := newTestGame(t, "normal", 7)
+			var out strings.Builder
+			outcome := play(g, lines(tt.commands...), NewPlainRenderer(&out), nil)
+			if outcome != OutcomeQuit || exitCode(outcome) != exitQuit {
+				t.Errorf("outcome %s, exit code %d", outcome, exitCode(outcome))
+			}
+			if !strings.Contains(out.String(), g.Player.CurrentRoom.Name) {
+				t.Errorf("the room was not shown: %q", out.String())
+			}
+		})
+	}
+}
+
+func TestPlayHeadlessWin(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	// Without an escape, solving the last quest wins
+	g.Config.Level.Escape = nil
+	quest := g.Player.Quests[0]
+	for _, q := range g.Player.Quests {
+		q.Solved = q != quest
+	}
+	quest.Requirements = nil
+	if room, _ := g.questLocation(quest); room != nil {
+		g.Player.CurrentRoom = room
+	}
+
+	var out strings.Builder
+	outcome := play(g, lines("start "+strconv.Itoa(quest.ID), quest.Solution), NewPlainRenderer(&out), nil)
+	if outcome != OutcomeWon || exitCode(outcome) != exitWon {
+		t.Errorf("outcome %s, exit code %d", outcome, exitCode(outcome))
+	}
+}
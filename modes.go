@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Mode holds the rules that change between game modes.
+type Mode struct {
+	Name              string
+	Description       string
+	BudgetPercent     int  // share of the clock budget the player gets
+	EnforceTimeLimits bool // a quest fails when its time limit runs out
+	UnlimitedEnergy   bool // energy is never used up
+	Exhaustion        bool // running out of energy loses the game
+	WrongAnswerEnergy int  // energy lost on a wrong or late answer
+	Hints             bool // hints can be revealed
+	HintEnergy        int  // energy a hint costs
+	HintXPPenalty     int  // experience lost per hint and difficulty level
+	Guided            bool // suggest the next step after every look
+	SingleSave        bool // one save slot, used up when loaded
+	ScorePercent      int  // share of the score the player gets
+}
+
+// modes are the modes a game can be played in, easiest first.
+var modes = []Mode{
+	{
+		Name:          "tutorial",
+		Description:   "guided prompts, no quest time limits, unlimited energy and free hints",
+		BudgetPercent: 100,
+		Hints:         true,
+		Guided:        true,
+		ScorePercent:  50,
+		// Energy is never used up, so nothing else costs any
+		UnlimitedEnergy: true,
+	},
+	{
+		Name:              "normal",
+		Description:       "the standard rules",
+		BudgetPercent:     100,
+		EnforceTimeLimits: true,
+		WrongAnswerEnergy: 10,
+		Hints:             true,
+		HintEnergy:        hintEnergyCost,
+		HintXPPenalty:     hintXPPenalty,
+		ScorePercent:      100,
+	},
+	{
+		Name:              "hardcore",
+		Description:       "half the time, harsher penalties, no hints, fatal exhaustion and a single save",
+		BudgetPercent:     50,
+		EnforceTimeLimits: true,
+		WrongAnswerEnergy: 25,
+		Exhaustion:        true,
+		SingleSave:        true,
+		ScorePercent:      150,
+	},
+}
+
+const defaultMode = "normal"
+
+// hardcoreSlot is the only save slot of a single-save game.
+const hardcoreSlot = "hardcore"
+
+// findMode looks a mode up by name.
+func findMode(name string) (Mode, bool) {
+	for _, mode := range modes {
+		if mode.Name == name {
+			return mode, true
+		}
+	}
+	return Mode{}, false
+}
+
+// modeNames lists the valid mode names for error messages.
+func modeNames() string {
+	names := make([]string, len(modes))
+	for i, mode := range modes {
+		names[i] = mode.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// mode returns the rules of the game's mode; saves from before modes
+// existed play by the normal rules.
+func (g *Game) mode() Mode {
+	if mode, ok := findMode(g.GameMode); ok {
+		return mode
+	}
+	mode, _ := findMode(defaultMode)
+	return mode
+}
+
+// spendEnergy uses up energy, unless the mode gives unlimited energy.
+func (g *Game) spendEnergy(amount int) {
+	if g.mode().UnlimitedEnergy {
+		return
+	}
+	g.Player.Stats.Energy -= amount
+	if g.Player.Stats.Energy < 0 {
+		g.Player.Stats.Energy = 0
+	}
+}
+
+// guide suggests what to do next in guided modes.
+func (g *Game) guide() {
+	if !g.mode().Guided {
+		return
+	}
+
+	room := g.Player.CurrentRoom
+	if e := g.escape(); e != nil && g.Rooms[e.Room] == room {
+		g.message(EventInfo, "👉 Tutorial: this is the way out. Type 'escape' to see the puzzle and the clues you earned, then 'escape <code>'.")
+		return
+	}
+	if len(room.Items) > 0 {
+		g.message(EventInfo, fmt.Sprintf("👉 Tutorial: items can help you. Try 'take %s', then 'inventory'.", room.Items[0].Name))
+		return
+	}
+	for _, quest := range g.Player.Quests {
+		if !quest.Solved && len(g.missingRequirements(quest)) == 0 {
+			g.message(EventInfo, fmt.Sprintf("👉 Tutorial: quest %d (%s) is ready. Type 'start %d'; 'hint %d' is free in tutorial mode.", quest.ID, quest.Name, quest.ID, quest.ID))
+			return
+		}
+	}
+	if len(room.Exits) > 0 {
+		g.message(EventInfo, "👉 Tutorial: your other quests need other rooms or items. Explore with 'go <direction>', and check 'quests' to see what each one needs.")
+	}
+}
+
+// removeSingleSave deletes the save of a single-save game, so a lost game
+// cannot be resumed.
+func (g *Game) removeSingleSave() {
+	if !g.mode().SingleSave || g.world != nil {
+		return
+	}
+	if path, err := g.slotPath(hardcoreSlot); err == nil {
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestModeRules(t *testing.T) {
+	tests := []struct {
+		mode   string
+		budget time.Duration
+		energy int // energy left after a move
+	}{
+		{"tutorial", 60 * time.Minute, 100},
+		{"normal", 60 * time.Minute, 98},
+		{"hardcore", 30 * time.Minute, 98},
+		{"unknown", 60 * time.Minute, 98}, // falls back to normal
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			g, _ := newTestGame(t, tt.mode, 7)
+			if got := g.budget(); got != tt.budget {
+				t.Errorf("budget %s, want %s", got, tt.budget)
+			}
+			run(g, "go north")
+			if got := g.Player.Stats.Energy; got != tt.energy {
+				t.Errorf("energy %d after a move, want %d", got, tt.energy)
+			}
+		})
+	}
+}
+
+func TestHardcoreSaveSlot(t *testing.T) {
+	g, _ := newTestGame(t, "hardcore", 7)
+	if err := g.Save("other"); err == nil {
+		t.Error("a hardcore game saved to a slot other than hardcore")
+	}
+	if err := g.Save(hardcoreSlot); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	// Until it is loaded, the single save may be overwritten
+	if err := g.Save(hardcoreSlot); err != nil {
+		t.Fatalf("second Save before loading: %v", err)
+	}
+}
+
+func TestHardcoreSaveIsUsedUpByLoading(t *testing.T) {
+	g, _ := newTestGame(t, "hardcore", 7)
+	out := run(g, "save hardcore", "load hardcore", "save hardcore", "load hardcore")
+
+	if !strings.Contains(out, "cannot be saved again") {
+		t.Errorf("saving a loaded hardcore game was not refused:\n%s", out)
+	}
+	if !strings.Contains(out, `no save in slot "hardcore"`) {
+		t.Errorf("the single save could be loaded twice:\n%s", out)
+	}
+}
+
+func TestLosingDeletesTheSingleSave(t *testing.T) {
+	g, _ := newTestGame(t, "hardcore", 7)
+	if err := g.Save(hardcoreSlot); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	g.finish(OutcomeLost)
+	if err := g.Load(hardcoreSlot); err == nil {
+		t.Error("the save of a lost hardcore game could still be loaded")
+	}
+}
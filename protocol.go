@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ProtocolCommand is one request of the JSON protocol, e.g.
+// {"cmd":"start","quest":21,"answer":"A→B→C→D→E"}. Only the fields the
+// command needs are read.
+type ProtocolCommand struct {
+	Cmd       string `json:"cmd"`
+	Item      string `json:"item,omitempty"`      // take, use
+	Target    string `json:"target,omitempty"`    // use: a direction or quest ID
+	Direction string `json:"direction,omitempty"` // go
+	Quest     int    `json:"quest,omitempty"`     // start, hint, hints
+	Answer    string `json:"answer,omitempty"`    // start, answer, escape: the airlock code
+	Reveal    bool   `json:"reveal,omitempty"`    // hint: confirm the answer-revealing hint
+	Slot      string `json:"slot,omitempty"`      // save, load
+	Minutes   int    `json:"minutes,omitempty"`   // rest
+	Mode      string `json:"mode,omitempty"`      // leaderboard
+	Seed      int64  `json:"seed,omitempty"`      // leaderboard
+	Input     string `json:"input,omitempty"`     // raw: a command typed as in the terminal
+}
+
+// ProtocolResponse answers a ProtocolCommand with what happened and the
+// state the game is in now.
+type ProtocolResponse struct {
+	OK      bool      `json:"ok"`
+	Error   string    `json:"error,omitempty"`
+	Events  []Event   `json:"events"`
+	Outcome string    `json:"outcome"`
+	Prompt  Prompt    `json:"prompt"`
+	State   GameState `json:"state"`
+}
+
+// GameState describes everything a player can see.
+type GameState struct {
+	Room        *RoomView   `json:"room"`
+	Inventory   []ItemView  `json:"inventory"`
+	Quests      []QuestView `json:"quests"`
+	Stats       StatsView   `json:"stats"`
+	ActiveQuest int         `json:"active_quest,omitempty"` // quest waiting for an answer
+}
+
+func (g *Game) state() GameState {
+	state := GameState{
+		Room:      g.roomView(),
+		Inventory: itemViews(g.Player.Inventory),
+		Quests:    g.questViews(),
+		Stats:     g.statsView(),
+	}
+	if g.activeQuest != nil {
+		state.ActiveQuest = g.activeQuest.ID
+	}
+	return state
+}
+
+// protocolInput translates a protocol command into the lines a player
+// would type. Commands that need an argument fail without it.
+func (g *Game) protocolInput(c ProtocolCommand) ([]string, error) {
+	need := func(value, name string) error {
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("%q needs %q", c.Cmd, name)
+		}
+		return nil
+	}
+	needQuest := func() error {
+		if c.Quest <= 0 {
+			return fmt.Errorf("%q needs a positive \"quest\"", c.Cmd)
+		}
+		return nil
+	}
+	quest := strconv.Itoa(c.Quest)
+
+	// While a quest is started every line is taken as its answer
+	if g.activeQuest != nil && c.Cmd != "answer" && c.Cmd != "raw" {
+		return nil, fmt.Errorf("quest %d is waiting for an answer, send \"answer\" first", g.activeQuest.ID)
+	}
+
+	switch c.Cmd {
+	case "look", "inventory", "quests", "stats", "help", "quit":
+		return []string{c.Cmd}, nil
+	case "continue":
+		return []string{""}, nil
+	case "take":
+		return []string{"take " + c.Item}, need(c.Item, "item")
+	case "use":
+		if c.Target != "" {
+			return []string{"use " + c.Item + " on " + c.Target}, need(c.Item, "item")
+		}
+		return []string{"use " + c.Item}, need(c.Item, "item")
+	case "go":
+		return []string{"go " + c.Direction}, need(c.Direction, "direction")
+	case "start":
+		if c.Answer == "" {
+			return []string{"start " + quest}, needQuest()
+		}
+		return []string{"start " + quest, c.Answer}, needQuest()
+	case "answer":
+		if g.activeQuest == nil {
+			return nil, fmt.Errorf("no quest is waiting for an answer, send \"start\" first")
+		}
+		return []string{c.Answer}, need(c.Answer, "answer")
+	case "hint":
+		if c.Reveal {
+			return []string{"hint " + quest + " reveal"}, needQuest()
+		}
+		return []string{"hint " + quest}, needQuest()
+	case "hints":
+		return []string{"hints " + quest}, needQuest()
+	case "rest":
+		if c.Minutes < 0 {
+			return nil, fmt.Errorf("\"minutes\" must be positive")
+		}
+		if c.Minutes == 0 {
+			return []string{"rest"}, nil
+		}
+		return []string{"rest " + strconv.Itoa(c.Minutes)}, nil
+	case "escape":
+		if c.Answer == "" {
+			return []string{"escape"}, nil
+		}
+		return []string{"escape " + c.Answer}, nil
+	case "leaderboard":
+		line := "leaderboard"
+		if c.Mode != "" {
+			line += " " + c.Mode
+		}
+		if c.Seed != 0 {
+			line += " " + strconv.FormatInt(c.Seed, 10)
+		}
+		return []string{line}, nil
+	case "save", "load":
+		return []string{c.Cmd + " " + c.Slot}, need(c.Slot, "slot")
+	case "raw":
+		return []string{c.Input}, nil
+	case "":
+		return nil, fmt.Errorf("missing \"cmd\"")
+	default:
+		return nil, fmt.Errorf("unknown command %q", c.Cmd)
+	}
+}
+
+// HandleProtocol runs one protocol command.
+func (g *Game) HandleProtocol(c ProtocolCommand) ProtocolResponse {
+	return g.handleProtocol(c, nil)
+}
+
+// handleProtocol runs one protocol command and records every line it plays
+// to rec.
+func (g *Game) handleProtocol(c ProtocolCommand, rec *Recorder) ProtocolResponse {
+	resp := ProtocolResponse{OK: true, Events: []Event{}}
+
+	inputs, err := g.protocolInput(c)
+	if err != nil || g.outcome != OutcomeContinue {
+		if err == nil {
+			err = fmt.Errorf("the game is over")
+		}
+		resp.OK = false
+		resp.Error = err.Error()
+		inputs = nil
+	}
+
+	for i, input := range inputs {
+		// The answer only goes in if the quest actually started
+		if i > 0 && g.activeQuest == nil {
+			resp.OK = false
+			resp.Error = "the quest could not be started"
+			break
+		}
+		result := g.ProcessCommand(input)
+		rec.Record(g, input, false)
+		resp.Events = append(resp.Events, contentEvents(result.Events)...)
+		if result.Outcome != OutcomeContinue {
+			break
+		}
+	}
+
+	resp.Outcome = g.outcome.String()
+	resp.Prompt = g.NextPrompt()
+	resp.State = g.state()
+	return resp
+}
+
+// serveProtocol drives the game with JSON commands read from in, one per
+// line, and writes a JSON response to out for each. The first response
+// describes the game before any command. Time only moves on when a command
+// arrives. The lines the commands play are recorded to rec.
+func serveProtocol(g *Game, in io.Reader, out io.Writer, rec *Recorder) Outcome {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.Encode(g.handleProtocol(ProtocolCommand{Cmd: "look"}, rec))
+
+	lines := bufio.NewScanner(in)
+	for lines.Scan() {
+		if strings.TrimSpace(lines.Text()) == "" {
+			continue
+		}
+		var c ProtocolCommand
+		if err := json.Unmarshal(lines.Bytes(), &c); err != nil {
+			enc.Encode(ProtocolResponse{
+				Error:   fmt.Sprintf("invalid command: %v", err),
+				Events:  []Event{},
+				Outcome: g.outcome.String(),
+				Prompt:  g.NextPrompt(),
+				State:   g.state(),
+			})
+			continue
+		}
+		enc.Encode(g.handleProtocol(c, rec))
+		if g.outcome != OutcomeContinue {
+			break
+		}
+	}
+	return g.outcome
+}
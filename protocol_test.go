@@ -0,0 +1,90 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProtocolInput(t *testing.T) {
+	tests := []struct {
+		cmd     ProtocolCommand
+		want    []string
+		wantErr bool
+	}{
+		{ProtocolCommand{Cmd: "look"}, []string{"look"}, false},
+		{ProtocolCommand{Cmd: "continue"}, []string{""}, false},
+		{ProtocolCommand{Cmd: "take", Item: "key"}, []string{"take key"}, false},
+		{ProtocolCommand{Cmd: "take"}, []string{"take "}, true},
+		{ProtocolCommand{Cmd: "use", Item: "key", Target: "north"}, []string{"use key on north"}, false},
+		{ProtocolCommand{Cmd: "go", Direction: "east"}, []string{"go east"}, false},
+		{ProtocolCommand{Cmd: "start", Quest: 21}, []string{"start 21"}, false},
+		{ProtocolCommand{Cmd: "start", Quest: 21, Answer: "A→B"}, []string{"start 21", "A→B"}, false},
+		{ProtocolCommand{Cmd: "start"}, []string{"start 0"}, true},
+		{ProtocolCommand{Cmd: "answer", Answer: "42"}, nil, true}, // nothing started
+		{ProtocolCommand{Cmd: "hint", Quest: 1, Reveal: true}, []string{"hint 1 reveal"}, false},
+		{ProtocolCommand{Cmd: "rest"}, []string{"rest"}, false},
+		{ProtocolCommand{Cmd: "rest", Minutes: 10}, []string{"rest 10"}, false},
+		{ProtocolCommand{Cmd: "rest", Minutes: -1}, nil, true},
+		{ProtocolCommand{Cmd: "escape"}, []string{"escape"}, false},
+		{ProtocolCommand{Cmd: "escape", Answer: "4321"}, []string{"escape 4321"}, false},
+		{ProtocolCommand{Cmd: "leaderboard"}, []string{"leaderboard"}, false},
+		{ProtocolCommand{Cmd: "leaderboard", Mode: "hardcore", Seed: 42}, []string{"leaderboard hardcore 42"}, false},
+		{ProtocolCommand{Cmd: "save", Slot: "a"}, []string{"save a"}, false},
+		{ProtocolCommand{Cmd: "raw", Input: "inventory"}, []string{"inventory"}, false},
+		{ProtocolCommand{}, nil, true},
+		{ProtocolCommand{Cmd: "dance"}, nil, true},
+	}
+	g, _ := newTestGame(t, "normal", 7)
+	for _, tt := range tests {
+		got, err := g.protocolInput(tt.cmd)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%+v: error %v, want error %v", tt.cmd, err, tt.wantErr)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%+v: lines %q, want %q", tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestProtocolStartAndAnswer(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	quest := g.Player.Quests[0]
+	quest.Requirements = nil
+	if room, _ := g.questLocation(quest); room != nil {
+		g.Player.CurrentRoom = room
+	}
+
+	resp := g.HandleProtocol(ProtocolCommand{Cmd: "start", Quest: quest.ID})
+	if !resp.OK || resp.State.ActiveQuest != quest.ID {
+		t.Fatalf("start: ok %v, active %d, error %q", resp.OK, resp.State.ActiveQuest, resp.Error)
+	}
+	if resp := g.HandleProtocol(ProtocolCommand{Cmd: "look"}); resp.OK {
+		t.Error("look was accepted while a quest waits for its answer")
+	}
+	resp = g.HandleProtocol(ProtocolCommand{Cmd: "answer", Answer: quest.Solution})
+	if !resp.OK || !quest.Solved || resp.State.ActiveQuest != 0 {
+		t.Fatalf("answer: ok %v, solved %v, error %q", resp.OK, quest.Solved, resp.Error)
+	}
+}
+
+func TestProtocolRest(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	g.Player.Stats.Energy = 50
+	resp := g.HandleProtocol(ProtocolCommand{Cmd: "rest", Minutes: 2})
+	if !resp.OK {
+		t.Fatalf("rest: %s", resp.Error)
+	}
+	if resp.State.Stats.Energy != 60 {
+		t.Errorf("energy %d after resting 2 minutes, want 60", resp.State.Stats.Energy)
+	}
+}
+
+func TestProtocolRejectsCommandsAfterTheEnd(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	if resp := g.HandleProtocol(ProtocolCommand{Cmd: "quit"}); resp.Outcome != OutcomeQuit.String() {
+		t.Fatalf("outcome %q after quit", resp.Outcome)
+	}
+	if resp := g.HandleProtocol(ProtocolCommand{Cmd: "look"}); resp.OK {
+		t.Error("a command was accepted after the game ended")
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Requirement is a prerequisite for starting a quest. In quest files it is
+// written as "item:<name>", "room:<room key>", "stat:<stat>>=<value>" or
+// "quest:<id>"; a bare name means an item.
+type Requirement struct {
+	Kind  string // "item", "room", "stat" or "quest"
+	Name  string // item name, room key or stat name
+	Value int    // minimum stat value or required quest ID
+}
+
+// statNames lists the stats a requirement can test.
+var statNames = []string{"hacking", "engineering", "astronomy", "biology", "physics", "energy"}
+
+// parseRequirement reads a requirement written in a quest file.
+func parseRequirement(spec string) (Requirement, error) {
+	kind, value, found := strings.Cut(spec, ":")
+	if !found {
+		kind, value = "item", spec
+	}
+	kind = strings.TrimSpace(strings.ToLower(kind))
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Requirement{}, fmt.Errorf("requirement %q names nothing", spec)
+	}
+
+	switch kind {
+	case "item", "room":
+		return Requirement{Kind: kind, Name: value}, nil
+	case "stat":
+		name, min, ok := strings.Cut(value, ">=")
+		if !ok {
+			return Requirement{}, fmt.Errorf("stat requirement %q must look like stat:hacking>=60", spec)
+		}
+		name = strings.TrimSpace(strings.ToLower(name))
+		known := false
+		for _, stat := range statNames {
+			known = known || stat == name
+		}
+		if !known {
+			return Requirement{}, fmt.Errorf("requirement %q names unknown stat %q", spec, name)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(min))
+		if err != nil {
+			return Requirement{}, fmt.Errorf("requirement %q has an invalid threshold", spec)
+		}
+		return Requirement{Kind: kind, Name: name, Value: n}, nil
+	case "quest":
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return Requirement{}, fmt.Errorf("requirement %q has an invalid quest ID", spec)
+		}
+		return Requirement{Kind: kind, Value: id}, nil
+	default:
+		return Requirement{}, fmt.Errorf("requirement %q has unknown kind %q", spec, kind)
+	}
+}
+
+// questRequirements parses the requirements of a quest. They were
+// validated when the quest was loaded.
+func questRequirements(quest *Quest) []Requirement {
+	reqs := make([]Requirement, 0, len(quest.Requirements))
+	for _, spec := range quest.Requirements {
+		if req, err := parseRequirement(spec); err == nil {
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs
+}
+
+// statValue returns the value of a stat by its requirement name.
+func (s *PlayerStats) statValue(name string) int {
+	switch name {
+	case "hacking":
+		return s.Hacking
+	case "engineering":
+		return s.Engineering
+	case "astronomy":
+		return s.Astronomy
+	case "biology":
+		return s.Biology
+	case "physics":
+		return s.Physics
+	case "energy":
+		return s.Energy
+	}
+	return 0
+}
+
+// hasItem reports whether the player carries an item with the given name.
+func (p *Player) hasItem(name string) bool {
+	for _, item := range p.Inventory {
+		if strings.EqualFold(item.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasQuest reports whether quest is one of the player's.
+func (p *Player) hasQuest(quest *Quest) bool {
+	for _, q := range p.Quests {
+		if q == quest {
+			return true
+		}
+	}
+	return false
+}
+
+// missingRequirements explains every requirement of quest the player does
+// not meet yet.
+func (g *Game) missingRequirements(quest *Quest) []string {
+	var missing []string
+	at, station := g.questLocation(quest)
+	if station != nil && g.Player.CurrentRoom != at {
+		missing = append(missing, fmt.Sprintf("you must be at the %s in the %s", station.Name, at.Name))
+	}
+	// A helper joins a co-op quest its owner already unlocked, so only has
+	// to be in the right place
+	helping := !g.hasQuest(quest)
+	for _, req := range questRequirements(quest) {
+		if req.Kind == "room" && station != nil && g.Rooms[req.Name] == at {
+			continue // already explained by the station
+		}
+		if helping && req.Kind != "room" {
+			continue
+		}
+		switch req.Kind {
+		case "item":
+			if !g.Player.hasItem(req.Name) {
+				missing = append(missing, fmt.Sprintf("you need the %s in your inventory", req.Name))
+			}
+		case "room":
+			room, ok := g.Rooms[req.Name]
+			if !ok || g.Player.CurrentRoom != room {
+				name := req.Name
+				if ok {
+					name = room.Name
+				}
+				missing = append(missing, fmt.Sprintf("you must be in the %s", name))
+			}
+		case "stat":
+			if have := g.Player.Stats.statValue(req.Name); have < req.Value {
+				missing = append(missing, fmt.Sprintf("%s must be at least %d (you have %d)", strings.ToUpper(req.Name[:1])+req.Name[1:], req.Value, have))
+			}
+		case "quest":
+			solved := false
+			name := ""
+			for _, q := range g.AllQuests {
+				if q.ID == req.Value {
+					solved, name = q.Solved, q.Name
+				}
+			}
+			if !solved {
+				missing = append(missing, fmt.Sprintf("solve quest %d (%s) first", req.Value, name))
+			}
+		}
+	}
+	return missing
+}
+
+// prerequisiteQuests returns the IDs of the quests that must be solved
+// before quest.
+func prerequisiteQuests(quest *Quest) []int {
+	var ids []int
+	for _, req := range questRequirements(quest) {
+		if req.Kind == "quest" {
+			ids = append(ids, req.Value)
+		}
+	}
+	return ids
+}
+
+// ValidateRequirements checks that quests only refer to rooms that exist in
+// the level and to quests that exist in the catalog, and that the stations
+// of the level host quests of the catalog that can be played there.
+func ValidateRequirements(level *Level, quests []*Quest) error {
+	rooms := make(map[string]bool, len(level.Rooms))
+	for _, room := range level.Rooms {
+		rooms[room.Key] = true
+	}
+	ids := make(map[int]bool, len(quests))
+	byID := make(map[int]*Quest, len(quests))
+	for _, quest := range quests {
+		ids[quest.ID] = true
+		byID[quest.ID] = quest
+	}
+
+	var errs LoadErrors
+	for _, quest := range quests {
+		for _, req := range questRequirements(quest) {
+			switch {
+			case req.Kind == "room" && !rooms[req.Name]:
+				errs = append(errs, LoadError{File: "quest " + strconv.Itoa(quest.ID), Msg: fmt.Sprintf("requires unknown room %q", req.Name)})
+			case req.Kind == "quest" && !ids[req.Value]:
+				errs = append(errs, LoadError{File: "quest " + strconv.Itoa(quest.ID), Msg: fmt.Sprintf("requires unknown quest %d", req.Value)})
+			}
+		}
+	}
+
+	// A station can only host a quest whose room requirement, if any, is
+	// the room of the station; otherwise the quest could never start
+	for i, room := range level.Rooms {
+		for _, station := range room.Stations {
+			for _, id := range station.Quests {
+				quest, ok := byID[id]
+				if !ok {
+					errs = append(errs, LoadError{File: level.file, Line: level.roomLine(i), Msg: fmt.Sprintf("station %q in room %q hosts unknown quest %d", station.Name, room.Key, id)})
+					continue
+				}
+				for _, req := range questRequirements(quest) {
+					if req.Kind == "room" && req.Name != room.Key {
+						errs = append(errs, LoadError{File: level.file, Line: level.roomLine(i), Msg: fmt.Sprintf("station %q in room %q hosts quest %d, which requires room %q", station.Name, room.Key, id, req.Name)})
+					}
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
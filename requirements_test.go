@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRequirement(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Requirement
+		wantErr bool
+	}{
+		{"Нейро-шлем", Requirement{Kind: "item", Name: "Нейро-шлем"}, false},
+		{"item: key", Requirement{Kind: "item", Name: "key"}, false},
+		{"room:observatory", Requirement{Kind: "room", Name: "observatory"}, false},
+		{"stat:Hacking >= 60", Requirement{Kind: "stat", Name: "hacking", Value: 60}, false},
+		{"quest:1", Requirement{Kind: "quest", Value: 1}, false},
+		{"stat:hacking", Requirement{}, true},
+		{"stat:charm>=5", Requirement{}, true},
+		{"stat:hacking>=lots", Requirement{}, true},
+		{"quest:first", Requirement{}, true},
+		{"skill:x", Requirement{}, true},
+		{"room:", Requirement{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseRequirement(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: error %v, want error %v", tt.spec, err, tt.wantErr)
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("%q: %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestMissingRequirements(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	quest := &Quest{ID: 900, Name: "test", Requirements: []string{"item:key", "room:observatory", "stat:hacking>=60", "quest:1"}}
+	g.AllQuests = append(g.AllQuests, quest)
+	g.Player.Quests = append(g.Player.Quests, quest)
+
+	if missing := g.missingRequirements(quest); len(missing) != 4 {
+		t.Fatalf("missing %q, want all 4 requirements", missing)
+	}
+
+	giveItem(g, "key", false)
+	g.Player.CurrentRoom = g.Rooms["observatory"]
+	g.Player.Stats.Hacking = 60
+	questByID(t, g, 1).Solved = true
+	if missing := g.missingRequirements(quest); len(missing) != 0 {
+		t.Errorf("missing %q with every requirement met", missing)
+	}
+}
+
+// writeLevel writes a level file with the given rooms, starting in the
+// first of them, and loads it.
+func writeLevel(t *testing.T, rooms string) *Level {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "level.json")
+	data := `{
+  "name": "test",
+  "start": "hall",
+  "rooms": [
+` + rooms + `
+  ]
+}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	level, err := LoadLevel(path)
+	if err != nil {
+		t.Fatalf("LoadLevel: %v", err)
+	}
+	return level
+}
+
+func TestValidateStations(t *testing.T) {
+	quests := []*Quest{
+		{ID: 1, Name: "anywhere"},
+		{ID: 2, Name: "in the lab", Requirements: []string{"room:lab"}},
+	}
+	tests := []struct {
+		name  string
+		rooms string
+		want  []string // line and message of every error
+	}{
+		{
+			name: "valid",
+			rooms: `    {"key": "hall", "exits": [{"direction": "north", "to": "lab", "back": "south"}],
+     "stations": [{"name": "desk", "quests": [1]}]},
+    {"key": "lab", "stations": [{"name": "bench", "quests": [2]}]}`,
+		},
+		{
+			name: "unknown quest",
+			rooms: `    {"key": "hall", "exits": [{"direction": "north", "to": "lab", "back": "south"}],
+     "stations": [{"name": "desk", "quests": [1, 7]}]},
+    {"key": "lab"}`,
+			want: []string{`:5: station "desk" in room "hall" hosts unknown quest 7`},
+		},
+		{
+			name: "room conflict",
+			rooms: `    {"key": "hall", "exits": [{"direction": "north", "to": "lab", "back": "south"}]},
+    {"key": "lab"},
+    {"key": "attic", "exits": [{"direction": "up", "to": "hall", "back": "down"}], "stations": [{"name": "chest", "quests": [2]}]}`,
+			want: []string{`:7: station "chest" in room "attic" hosts quest 2, which requires room "lab"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level := writeLevel(t, tt.rooms)
+			err := ValidateRequirements(level, quests)
+			var errs LoadErrors
+			if err != nil && !errors.As(err, &errs) {
+				t.Fatalf("error %v is not a LoadErrors", err)
+			}
+			if len(errs) != len(tt.want) {
+				t.Fatalf("errors %v, want %d", errs, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got := errs[i].Error(); !strings.HasPrefix(got, level.file) || !strings.HasSuffix(got, want) {
+					t.Errorf("error %q, want %s%s", got, level.file, want)
+				}
+			}
+		})
+	}
+}
@@ -1,5 +1,5 @@
 @echo off
 echo 🌌 Starting Cosmic Cyberpunk Room Escape...
 echo.
-go run main.go
+go run .
 pause
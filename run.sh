@@ -1,4 +1,4 @@
 #!/bin/bash
 echo "🌌 Starting Cosmic Cyberpunk Room Escape..."
 echo
-go run main.go
+go run .
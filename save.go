@@ -0,0 +1,398 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// saveVersion is the schema version written by Save. Bump it whenever the
+// layout of saveFile changes and register a migration from the old version.
+const saveVersion = 2
+
+// defaultSaveDir is where save slots are stored, one JSON file per slot,
+// unless the game is configured with another directory.
+const defaultSaveDir = "saves"
+
+var slotPattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// saveMigrations upgrade a raw save file from the keyed version to the next
+// one. Load applies them in order until the file reaches saveVersion.
+var saveMigrations = map[int]func(raw map[string]json.RawMessage) error{
+	1: migrateSaveV1,
+}
+
+// migrateSaveV1 derives the game clock, which schema 1 did not store, from
+// the wall time between the start of the game and the save.
+func migrateSaveV1(raw map[string]json.RawMessage) error {
+	var start, saved time.Time
+	if err := json.Unmarshal(raw["game_start"], &start); err != nil {
+		return fmt.Errorf("game_start: %w", err)
+	}
+	if err := json.Unmarshal(raw["saved_at"], &saved); err != nil {
+		return fmt.Errorf("saved_at: %w", err)
+	}
+
+	clock, err := json.Marshal(clockState{Elapsed: saved.Sub(start)})
+	if err != nil {
+		return err
+	}
+	raw["clock"] = clock
+	return nil
+}
+
+// saveFile is the on-disk snapshot of a whole Game.
+type saveFile struct {
+	Version   int                  `json:"version"`
+	SavedAt   time.Time            `json:"saved_at"`
+	GameStart time.Time            `json:"game_start"`
+	GameMode  string               `json:"game_mode"`
+	Seed      int64                `json:"seed,omitempty"`
+	Clock     clockState           `json:"clock"`
+	Player    playerState          `json:"player"`
+	Rooms     map[string]roomState `json:"rooms"`
+	Solved    []int                `json:"solved"`
+	Seeds     map[int]int64        `json:"seeds,omitempty"` // seeds of generated quests by ID
+}
+
+// clockState stores how much of the time budget has been used.
+type clockState struct {
+	Elapsed time.Duration `json:"elapsed"` // play time, not counting menus
+	Penalty time.Duration `json:"penalty"` // time charged for actions
+}
+
+type playerState struct {
+	Room          string      `json:"room"`
+	Inventory     []*Item     `json:"inventory"`
+	Stats         PlayerStats `json:"stats"`
+	Quests        []int       `json:"quests"`
+	Completed     int         `json:"completed"`
+	HintsUsed     map[int]int `json:"hints_used,omitempty"`
+	WrongAttempts int         `json:"wrong_attempts"`
+}
+
+// roomState stores a room with its exits flattened to room keys, so the
+// pointer graph can be re-linked on load.
+type roomState struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Items       []*Item           `json:"items"`
+	Exits       map[string]string `json:"exits"`
+	Locks       map[string]string `json:"locks,omitempty"`
+	Hidden      []*Item           `json:"hidden,omitempty"`
+	Requires    []string          `json:"requires,omitempty"`
+	Stations    []*Station        `json:"stations,omitempty"`
+	Solved      bool              `json:"solved"`
+	ASCII       string            `json:"ascii"`
+}
+
+// saveDir returns the directory the game keeps its save slots in.
+func (g *Game) saveDir() string {
+	if g.Config.SaveDir != "" {
+		return g.Config.SaveDir
+	}
+	return defaultSaveDir
+}
+
+func (g *Game) slotPath(slot string) (string, error) {
+	if !slotPattern.MatchString(slot) {
+		return "", fmt.Errorf("invalid slot name %q: use letters, digits, '-' or '_'", slot)
+	}
+	return filepath.Join(g.saveDir(), slot+".json"), nil
+}
+
+// roomKey returns the key under which room is stored in g.Rooms.
+func (g *Game) roomKey(room *Room) string {
+	for key, r := range g.Rooms {
+		if r == room {
+			return key
+		}
+	}
+	return ""
+}
+
+// snapshot captures the current game state in its serialisable form.
+func (g *Game) snapshot() (*saveFile, error) {
+	s := &saveFile{
+		Version:   saveVersion,
+		SavedAt:   time.Now(),
+		GameStart: g.GameStart,
+		GameMode:  g.GameMode,
+		Seed:      g.Seed,
+		Clock:     clockState{Elapsed: g.elapsed(), Penalty: g.clock.penalty},
+		Rooms:     make(map[string]roomState, len(g.Rooms)),
+	}
+
+	for key, room := range g.Rooms {
+		exits := make(map[string]string, len(room.Exits))
+		for direction, target := range room.Exits {
+			targetKey := g.roomKey(target)
+			if targetKey == "" {
+				return nil, fmt.Errorf("room %q has an exit %s to an unknown room", key, direction)
+			}
+			exits[direction] = targetKey
+		}
+		s.Rooms[key] = roomState{
+			Name:        room.Name,
+			Description: room.Description,
+			Items:       room.Items,
+			Exits:       exits,
+			Locks:       room.Locks,
+			Hidden:      room.Hidden,
+			Requires:    room.Requires,
+			Stations:    room.Stations,
+			Solved:      room.Solved,
+			ASCII:       room.ASCII,
+		}
+	}
+
+	s.Player = playerState{
+		Room:          g.roomKey(g.Player.CurrentRoom),
+		Inventory:     g.Player.Inventory,
+		Stats:         *g.Player.Stats,
+		Completed:     g.Player.Completed,
+		HintsUsed:     g.Player.HintsUsed,
+		WrongAttempts: g.Player.WrongAttempts,
+	}
+	for _, quest := range g.Player.Quests {
+		s.Player.Quests = append(s.Player.Quests, quest.ID)
+	}
+	for _, quest := range g.AllQuests {
+		if quest.Solved {
+			s.Solved = append(s.Solved, quest.ID)
+		}
+		if quest.Generator != "" {
+			if s.Seeds == nil {
+				s.Seeds = make(map[int]int64)
+			}
+			s.Seeds[quest.ID] = quest.Seed
+		}
+	}
+
+	return s, nil
+}
+
+// errMultiplayerSave is returned by Save and Load in a shared world, which
+// no single player can save or restore.
+var errMultiplayerSave = errors.New("saving is not available in multiplayer")
+
+// Save writes the game to the given slot.
+func (g *Game) Save(slot string) error {
+	if g.world != nil {
+		return errMultiplayerSave
+	}
+	if g.mode().SingleSave && slot != hardcoreSlot {
+		return fmt.Errorf("%s games have a single save: use slot %q", g.GameMode, hardcoreSlot)
+	}
+	if g.saveUsed {
+		return fmt.Errorf("this %s game was loaded from its single save and cannot be saved again", g.GameMode)
+	}
+	path, err := g.slotPath(slot)
+	if err != nil {
+		return err
+	}
+
+	s, err := g.snapshot()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(g.saveDir(), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a torn save.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readSave reads a slot and migrates it to the current schema version.
+func (g *Game) readSave(slot string) (*saveFile, error) {
+	path, err := g.slotPath(slot)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no save in slot %q", slot)
+		}
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("slot %q is corrupt: %w", slot, err)
+	}
+
+	version := 0
+	if v, ok := raw["version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			return nil, fmt.Errorf("slot %q has an invalid version: %w", slot, err)
+		}
+	}
+	if version > saveVersion {
+		return nil, fmt.Errorf("slot %q was written by a newer version of the game (schema %d)", slot, version)
+	}
+
+	for version < saveVersion {
+		migrate, ok := saveMigrations[version]
+		if !ok {
+			return nil, fmt.Errorf("slot %q uses schema %d which can no longer be loaded", slot, version)
+		}
+		if err := migrate(raw); err != nil {
+			return nil, fmt.Errorf("migrating slot %q from schema %d: %w", slot, version, err)
+		}
+		version++
+		raw["version"], _ = json.Marshal(version)
+	}
+
+	data, err = json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var s saveFile
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("slot %q is corrupt: %w", slot, err)
+	}
+	return &s, nil
+}
+
+// Load replaces the game state with the one stored in the given slot.
+func (g *Game) Load(slot string) error {
+	if g.world != nil {
+		return errMultiplayerSave
+	}
+	s, err := g.readSave(slot)
+	if err != nil {
+		return err
+	}
+
+	rooms := make(map[string]*Room, len(s.Rooms))
+	for key, rs := range s.Rooms {
+		items := rs.Items
+		if items == nil {
+			items = []*Item{}
+		}
+		locks := rs.Locks
+		if locks == nil {
+			locks = make(map[string]string)
+		}
+		rooms[key] = &Room{
+			Name:        rs.Name,
+			Description: rs.Description,
+			Items:       items,
+			Exits:       make(map[string]*Room, len(rs.Exits)),
+			Locks:       locks,
+			Hidden:      rs.Hidden,
+			Requires:    rs.Requires,
+			Stations:    rs.Stations,
+			Solved:      rs.Solved,
+			ASCII:       rs.ASCII,
+		}
+	}
+	for key, rs := range s.Rooms {
+		for direction, targetKey := range rs.Exits {
+			target, ok := rooms[targetKey]
+			if !ok {
+				return fmt.Errorf("slot %q: room %q has an exit %s to unknown room %q", slot, key, direction, targetKey)
+			}
+			rooms[key].Exits[direction] = target
+		}
+	}
+
+	current, ok := rooms[s.Player.Room]
+	if !ok {
+		return fmt.Errorf("slot %q: player is in unknown room %q", slot, s.Player.Room)
+	}
+
+	// Regenerate procedural quests from their saved seeds; quests the save
+	// does not know about keep the puzzle of the running game
+	playing := make(map[int]*Quest, len(g.AllQuests))
+	for _, quest := range g.AllQuests {
+		playing[quest.ID] = quest
+	}
+	allQuests := make([]*Quest, 0, len(g.Config.Quests))
+	questsByID := make(map[int]*Quest, len(g.Config.Quests))
+	for _, template := range g.Config.Quests {
+		seed, ok := s.Seeds[template.ID]
+		if !ok && playing[template.ID] != nil {
+			seed = playing[template.ID].Seed
+		}
+		quest := template.Generate(seed)
+		allQuests = append(allQuests, quest)
+		questsByID[quest.ID] = quest
+	}
+
+	playerQuests := make([]*Quest, 0, len(s.Player.Quests))
+	for _, id := range s.Player.Quests {
+		quest, ok := questsByID[id]
+		if !ok {
+			return fmt.Errorf("slot %q: unknown quest ID %d", slot, id)
+		}
+		playerQuests = append(playerQuests, quest)
+	}
+
+	// Everything is validated; only now touch the live game.
+	for _, id := range s.Solved {
+		if quest, ok := questsByID[id]; ok {
+			quest.Solved = true
+		}
+	}
+
+	inventory := s.Player.Inventory
+	if inventory == nil {
+		inventory = []*Item{}
+	}
+	stats := s.Player.Stats
+	hintsUsed := s.Player.HintsUsed
+	if hintsUsed == nil {
+		hintsUsed = make(map[int]int)
+	}
+
+	g.Rooms = rooms
+	g.AllQuests = allQuests
+	g.Player = &Player{
+		Name:          g.Player.Name,
+		CurrentRoom:   current,
+		Inventory:     inventory,
+		Stats:         &stats,
+		Quests:        playerQuests,
+		Completed:     s.Player.Completed,
+		HintsUsed:     hintsUsed,
+		WrongAttempts: s.Player.WrongAttempts,
+		CoopCredit:    make(map[int]int),
+	}
+	g.GameMode = s.GameMode
+	if s.Seed != 0 {
+		g.Seed = s.Seed
+	}
+	// Time spent while the game sat on disk does not count.
+	g.GameStart = g.now().Add(-s.Clock.Elapsed)
+	g.clock = gameClock{penalty: s.Clock.Penalty}
+	g.menuOpen = false
+	g.activeQuest = nil
+	g.syncClock()
+
+	// A single save is used up by loading it: losing means starting over,
+	// and the loaded game may not be saved again
+	g.saveUsed = g.mode().SingleSave
+	if g.saveUsed {
+		g.removeSingleSave()
+	}
+	return nil
+}
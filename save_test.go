@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+// writeOldSave saves g to slot and rewrites the file as a save of schema
+// version, which downgrade strips of what that schema did not store.
+func writeOldSave(t *testing.T, g *Game, slot string, version int, downgrade func(save map[string]any)) {
+	t.Helper()
+	if err := g.Save(slot); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	path, _ := g.slotPath(slot)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var save map[string]any
+	if err := json.Unmarshal(data, &save); err != nil {
+		t.Fatal(err)
+	}
+	save["version"] = version
+	downgrade(save)
+	if data, err = json.Marshal(save); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSaveRoundTrip(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	run(g, "go north", "take key")
+	g.Player.WrongAttempts = 2
+	if err := g.Save("round"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	h, _ := newTestGame(t, "normal", 99)
+	h.Config.SaveDir = g.Config.SaveDir
+	if err := h.Load("round"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if h.Player.CurrentRoom != h.Rooms["engineering bay"] {
+		t.Errorf("player is in %q, want the engineering bay", h.Player.CurrentRoom.Name)
+	}
+	if !h.Player.hasItem("key") {
+		t.Error("the key was lost")
+	}
+	if h.Player.WrongAttempts != 2 {
+		t.Errorf("%d wrong attempts, want 2", h.Player.WrongAttempts)
+	}
+	if h.Seed != g.Seed {
+		t.Errorf("seed %d, want %d", h.Seed, g.Seed)
+	}
+	want, _ := g.stateHash()
+	if got, _ := h.stateHash(); got != want {
+		t.Error("the loaded game differs from the saved one")
+	}
+}
+
+func TestLoadMigratesSchema1(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	writeOldSave(t, g, "old", 1, func(save map[string]any) {
+		delete(save, "clock")
+		start, _ := time.Parse(time.RFC3339Nano, save["game_start"].(string))
+		save["saved_at"] = start.Add(10 * time.Minute).Format(time.RFC3339Nano)
+	})
+	if err := g.Load("old"); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	// The clock of a schema 1 save is the wall time up to the save.
+	if got := g.elapsed(); got != 10*time.Minute {
+		t.Errorf("elapsed %s, want 10m", got)
+	}
+}
+
+func TestLoadRejectsNewerSaves(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	writeOldSave(t, g, "new", saveVersion+1, func(map[string]any) {})
+	if err := g.Load("new"); err == nil {
+		t.Fatal("Load accepted a save from a newer version")
+	}
+}
+
+func TestInvalidSlotNames(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	for _, slot := range []string{"", "../escape", "Slot", "a b"} {
+		if _, err := g.slotPath(slot); err == nil {
+			t.Errorf("slot %q was accepted", slot)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import "time"
+
+// Score components. The total is then scaled by the ScorePercent of the
+// mode and never drops below zero.
+const (
+	scorePerDifficulty = 100 // for every difficulty level of a solved quest
+	scorePerMinute     = 10  // for every minute left after escaping
+	scorePerEnergy     = 2   // for every point of energy left after escaping
+	scorePerHint       = 25  // taken off for every hint revealed, unless hints are free
+	scorePerWrong      = 30  // taken off for every wrong or late answer
+	scoreEscapeBonus   = 500
+)
+
+// hintsUsed counts every hint revealed in the game.
+func (g *Game) hintsUsed() int {
+	total := 0
+	for _, n := range g.Player.HintsUsed {
+		total += n
+	}
+	return total
+}
+
+// score rates the game so far. Time and energy left only count once the
+// player escaped.
+func (g *Game) score(escaped bool) int {
+	score := 0
+	for _, quest := range g.Player.Quests {
+		if quest.Solved {
+			score += quest.Difficulty * scorePerDifficulty
+		}
+	}
+	score -= g.Player.WrongAttempts * scorePerWrong
+	// Hints only cost points in modes where they cost energy or experience
+	if mode := g.mode(); (mode.HintEnergy > 0 && !mode.UnlimitedEnergy) || mode.HintXPPenalty > 0 {
+		score -= g.hintsUsed() * scorePerHint
+	}
+	if escaped {
+		stats := g.Player.Stats
+		score += scoreEscapeBonus + int(stats.TimeLeft/time.Minute)*scorePerMinute + stats.Energy*scorePerEnergy
+	}
+	score = score * g.mode().ScorePercent / 100
+	if score < 0 {
+		score = 0
+	}
+	return score
+}
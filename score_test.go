@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		hints   int
+		wrong   int
+		escaped bool
+		want    int
+	}{
+		{"solved quests", "normal", 0, 0, false, 500},
+		{"hints and wrong answers", "normal", 2, 3, false, 500 - 2*scorePerHint - 3*scorePerWrong},
+		{"escaped", "normal", 0, 0, true, 500 + scoreEscapeBonus + 20*scorePerMinute + 40*scorePerEnergy},
+		{"tutorial halves it", "tutorial", 0, 0, false, 250},
+		{"free hints cost no points", "tutorial", 2, 1, false, (500 - scorePerWrong) / 2},
+		{"hardcore adds half", "hardcore", 0, 0, false, 750},
+		{"never negative", "normal", 0, 50, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := newTestGame(t, tt.mode, 7)
+			// Quests of difficulty 2 and 3 are solved, the rest are not
+			for i, quest := range g.Player.Quests {
+				quest.Solved = i < 2
+			}
+			g.Player.Quests[0].Difficulty = 2
+			g.Player.Quests[1].Difficulty = 3
+			g.Player.HintsUsed[g.Player.Quests[0].ID] = tt.hints
+			g.Player.WrongAttempts = tt.wrong
+			g.Player.Stats.TimeLeft = 20*time.Minute + 59*time.Second
+			g.Player.Stats.Energy = 40
+
+			if got := g.score(tt.escaped); got != tt.want {
+				t.Errorf("score %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
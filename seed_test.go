@@ -0,0 +1,58 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// questPlan lists the IDs and solutions of the quests a game hands out.
+func questPlan(g *Game) []string {
+	var plan []string
+	for _, quest := range g.Player.Quests {
+		plan = append(plan, strconv.Itoa(quest.ID)+": "+quest.Solution)
+	}
+	return plan
+}
+
+func TestSeedPicksTheSameGame(t *testing.T) {
+	a, _ := newTestGame(t, "normal", 42)
+	b, _ := newTestGame(t, "normal", 42)
+	if !reflect.DeepEqual(questPlan(a), questPlan(b)) {
+		t.Errorf("seed 42 gave %q and %q", questPlan(a), questPlan(b))
+	}
+
+	differ := false
+	for seed := int64(1); seed <= 5 && !differ; seed++ {
+		c, _ := newTestGame(t, "normal", seed)
+		differ = !reflect.DeepEqual(questPlan(a), questPlan(c))
+	}
+	if !differ {
+		t.Error("seeds 1 to 5 all gave the game of seed 42")
+	}
+}
+
+func TestQuestsComeWithTheirPrerequisites(t *testing.T) {
+	for seed := int64(1); seed <= 30; seed++ {
+		g, _ := newTestGame(t, "normal", seed)
+		if len(g.Player.Quests) != 5 {
+			t.Errorf("seed %d: %d quests, want 5", seed, len(g.Player.Quests))
+		}
+		given := make(map[int]bool)
+		for _, quest := range g.Player.Quests {
+			for _, id := range prerequisiteQuests(quest) {
+				if !given[id] {
+					t.Errorf("seed %d: quest %d comes without quest %d before it", seed, quest.ID, id)
+				}
+			}
+			given[quest.ID] = true
+		}
+	}
+}
+
+func TestRandomSeedIsRecorded(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 0)
+	if g.Seed == 0 {
+		t.Error("a game without a seed did not record the one it picked")
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// World is the facility shared by every player connected to a server.
+// Each player plays their own Game with their own quests and clock, but the
+// rooms, their items and locks are shared. Every command runs with mu held,
+// so players never see each other half-way through a command.
+type World struct {
+	mu      sync.Mutex
+	cfg     GameConfig
+	rooms   map[string]*Room
+	start   *Room
+	players map[string]*Game     // by lower-case name
+	coop    map[int]*coopAttempt // open co-op attempts by quest ID
+}
+
+// NewWorld builds the shared rooms of cfg.Level.
+func NewWorld(cfg GameConfig) *World {
+	rooms, start := cfg.Level.Build()
+	return &World{cfg: cfg, rooms: rooms, start: start, players: make(map[string]*Game), coop: make(map[int]*coopAttempt)}
+}
+
+var playerNamePattern = regexp.MustCompile(`^[\p{L}\p{N}_-]{1,16}$`)
+
+// inboxSize is how many messages from other players a session buffers.
+// Messages beyond that are dropped rather than blocking the sender.
+const inboxSize = 32
+
+// Join adds a player to the world and returns their game.
+func (w *World) Join(name string) (*Game, error) {
+	if !playerNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("use 1-16 letters, digits, '-' or '_' for your name")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	key := strings.ToLower(name)
+	if _, taken := w.players[key]; taken {
+		return nil, fmt.Errorf("%s is already playing, pick another name", name)
+	}
+
+	g := NewGame(w.cfg)
+	g.Rooms = w.rooms
+	g.Player.CurrentRoom = w.start
+	g.Player.Name = name
+	g.world = w
+	g.inbox = make(chan []Event, inboxSize)
+
+	w.players[key] = g
+	g.announce(w.start, fmt.Sprintf("%s enters the facility.", name))
+	return g, nil
+}
+
+// Leave removes a player from the world.
+func (w *World) Leave(g *Game) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.players, strings.ToLower(g.Player.Name))
+	g.announce(g.Player.CurrentRoom, fmt.Sprintf("%s has left the facility.", g.Player.Name))
+}
+
+// lock holds the world, if any, for the duration of a command.
+func (g *Game) lock() func() {
+	if g.world == nil {
+		return func() {}
+	}
+	g.world.mu.Lock()
+	return g.world.mu.Unlock
+}
+
+// tell delivers events to another player.
+func (g *Game) tell(to *Game, events ...Event) {
+	select {
+	case to.inbox <- events:
+	default:
+	}
+}
+
+// announce tells every other player in room what happened.
+func (g *Game) announce(room *Room, text string) {
+	if g.world == nil {
+		return
+	}
+	for _, other := range g.world.players {
+		if other != g && other.Player.CurrentRoom == room {
+			g.tell(other, Event{Kind: EventInfo, Text: text})
+		}
+	}
+}
+
+// othersHere returns the names of the other players in the player's room.
+func (g *Game) othersHere() []string {
+	if g.world == nil {
+		return nil
+	}
+	var names []string
+	for _, other := range g.world.players {
+		if other != g && other.Player.CurrentRoom == g.Player.CurrentRoom {
+			names = append(names, other.Player.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Say speaks to every player in the same room.
+func (g *Game) Say(text string) {
+	if len(g.othersHere()) == 0 {
+		g.message(EventInfo, "There is nobody here to hear you.")
+		return
+	}
+	g.announce(g.Player.CurrentRoom, fmt.Sprintf("💬 %s says: %s", g.Player.Name, text))
+	g.message(EventInfo, fmt.Sprintf("💬 You say: %s", text))
+}
+
+// Whisper speaks to one player, wherever they are.
+func (g *Game) Whisper(name, text string) {
+	var to *Game
+	if g.world != nil {
+		to = g.world.players[strings.ToLower(name)]
+	}
+	if to == nil || to == g {
+		g.message(EventError, fmt.Sprintf("There is no other player called %s.", name))
+		return
+	}
+	g.tell(to, Event{Kind: EventInfo, Text: fmt.Sprintf("🤫 %s whispers: %s", g.Player.Name, text)})
+	g.message(EventInfo, fmt.Sprintf("🤫 You whisper to %s: %s", to.Player.Name, text))
+}
+
+// Serve accepts players on ln until it fails.
+func (w *World) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go w.handle(conn)
+	}
+}
+
+// handle plays one session over a connection, with its own scanner and
+// renderer.
+func (w *World) handle(conn net.Conn) {
+	defer conn.Close()
+
+	in := bufio.NewScanner(conn)
+	term := NewTerminalRenderer(conn)
+	term.Render([]Event{
+		{Kind: EventClear},
+		{Kind: EventBanner},
+		{Kind: EventText},
+		{Kind: EventInfo, Text: "🌌 Welcome to the Cosmic Cyberpunk Room Escape!"},
+		{Kind: EventInfo, Text: "Other players roam the facility with you. Type 'help' for commands."},
+	})
+
+	var g *Game
+	for g == nil {
+		fmt.Fprint(conn, "\nWhat is your name? ")
+		if !in.Scan() {
+			return
+		}
+		var err error
+		if g, err = w.Join(strings.TrimSpace(in.Text())); err != nil {
+			term.Render([]Event{{Kind: EventError, Text: err.Error()}})
+		}
+	}
+	defer w.Leave(g)
+
+	done := make(chan struct{})
+	defer close(done)
+	play(g, readLines(in, done), term, nil)
+}
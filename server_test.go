@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// inboxText returns the text of every message waiting for g.
+func inboxText(g *Game) string {
+	var out []string
+	for {
+		select {
+		case events := <-g.inbox:
+			out = append(out, eventText(Result{Events: events}))
+		default:
+			return strings.Join(out, "\n")
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	cfg, _ := testConfig(t, "normal", 42)
+	w := NewWorld(cfg)
+	alice, err := w.Join("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"", "ALICE", "a b", "a-very-long-player-name"} {
+		if _, err := w.Join(name); err == nil {
+			t.Errorf("%q could join", name)
+		}
+	}
+
+	bob, err := w.Join("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(inboxText(alice), "bob enters the facility") {
+		t.Error("alice was not told that bob entered")
+	}
+	w.Leave(bob)
+	if !strings.Contains(inboxText(alice), "bob has left the facility") {
+		t.Error("alice was not told that bob left")
+	}
+	if _, err := w.Join("Bob"); err != nil {
+		t.Errorf("the name of a player who left is still taken: %v", err)
+	}
+}
+
+func TestPlayersShareTheRooms(t *testing.T) {
+	cfg, _ := testConfig(t, "normal", 42)
+	w := NewWorld(cfg)
+	alice, _ := w.Join("alice")
+	bob, _ := w.Join("bob")
+
+	room := alice.Player.CurrentRoom
+	if len(room.Items) == 0 {
+		t.Fatal("the start room has no items")
+	}
+	item := room.Items[0]
+	run(bob, "take "+item.Name)
+	if !bob.Player.hasItem(item.Name) {
+		t.Fatalf("bob could not take the %s", item.Name)
+	}
+	if out := run(alice, "take "+item.Name); alice.Player.hasItem(item.Name) {
+		t.Errorf("alice took the %s bob already has: %q", item.Name, out)
+	}
+}
+
+func TestSayAndWhisper(t *testing.T) {
+	cfg, _ := testConfig(t, "normal", 42)
+	w := NewWorld(cfg)
+	alice, _ := w.Join("alice")
+	bob, _ := w.Join("bob")
+	inboxText(alice)
+
+	run(bob, "say hello")
+	if got := inboxText(alice); !strings.Contains(got, "bob says: hello") {
+		t.Errorf("alice heard %q", got)
+	}
+
+	run(bob, "go north")
+	inboxText(alice)
+	if out := run(bob, "say anyone?"); !strings.Contains(out, "nobody here") {
+		t.Errorf("bob talked to an empty room: %q", out)
+	}
+	run(bob, "whisper alice over here")
+	if got := inboxText(alice); !strings.Contains(got, "bob whispers: over here") {
+		t.Errorf("alice did not get the whisper from another room: %q", got)
+	}
+	if out := run(bob, "whisper carol hi"); !strings.Contains(out, "no other player called carol") {
+		t.Errorf("whispering to nobody said %q", out)
+	}
+}
+
+func TestServe(t *testing.T) {
+	cfg, _ := testConfig(t, "normal", 42)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer ln.Close()
+	go NewWorld(cfg).Serve(ln)
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	in := bufio.NewReader(conn)
+	readUntil := func(want string) {
+		t.Helper()
+		var seen strings.Builder
+		for !strings.Contains(seen.String(), want) {
+			b, err := in.ReadByte()
+			if err != nil {
+				t.Fatalf("%v before %q in %q", err, want, seen.String())
+			}
+			seen.WriteByte(b)
+		}
+	}
+
+	readUntil("What is your name?")
+	io.WriteString(conn, "a b\n")
+	readUntil("What is your name?")
+	io.WriteString(conn, "alice\nsay hi\n")
+	readUntil("nobody here")
+	conn.(*net.TCPConn).CloseWrite()
+	if _, err := io.ReadAll(in); err != nil {
+		t.Errorf("the session did not end with the input: %v", err)
+	}
+}
+
+func TestPromptWhileOthersPlay(t *testing.T) {
+	cfg, _ := testConfig(t, "normal", 42)
+	w := NewWorld(cfg)
+	alice, _ := w.Join("alice")
+	bob, _ := w.Join("bob")
+	quest := alice.Player.Quests[0]
+	alice.activeQuest = quest
+	alice.attempt = questAttempt{started: alice.now()}
+
+	// Completing a co-op quest gives alice experience during bob's command
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 100; i++ {
+			unlock := bob.lock()
+			alice.addStat(quest.Category, 1)
+			unlock()
+		}
+		close(done)
+	}()
+	for i := 0; i < 100; i++ {
+		if prompt := alice.NextPrompt(); prompt.Mode != PromptAnswer {
+			t.Fatalf("prompt %s while a quest waits for its answer", prompt.Mode)
+		}
+	}
+	<-done
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxSkill caps every category skill.
+const maxSkill = 100
+
+// Skill perks for the category of a quest. They add up: a skill of 80
+// gives all three.
+const (
+	perkTimeSkill   = 60 // quest time limits are longer
+	perkEnergySkill = 70 // answers cost half the energy
+	perkHintSkill   = 80 // the first hint of a quest is free
+
+	perkTimePercent = 25 // extra time limit with the time perk
+)
+
+// rank is a level the player reaches once their experience gets to XP.
+type rank struct {
+	XP    int
+	Title string
+}
+
+// ranks are the levels in order; the level is the index plus one.
+var ranks = []rank{
+	{0, "Cadet"},
+	{15, "Technician"},
+	{40, "Specialist"},
+	{75, "Engineer"},
+	{120, "Netrunner"},
+	{180, "Quantum Architect"},
+}
+
+// levelFor returns the level and rank reached with xp experience.
+func levelFor(xp int) (int, rank) {
+	level := 1
+	for i, r := range ranks {
+		if xp >= r.XP {
+			level = i + 1
+		}
+	}
+	return level, ranks[level-1]
+}
+
+// skill returns a pointer to the skill that belongs to a quest category.
+func (g *Game) skill(category QuestCategory) *int {
+	stats := g.Player.Stats
+	switch category {
+	case HackerQuest:
+		return &stats.Hacking
+	case EngineeringQuest:
+		return &stats.Engineering
+	case AstronomicalQuest:
+		return &stats.Astronomy
+	case BiologicalQuest:
+		return &stats.Biology
+	case PhysicalQuest:
+		return &stats.Physics
+	}
+	return nil
+}
+
+// skillAtLeast reports whether the skill of category has reached level.
+func (g *Game) skillAtLeast(category QuestCategory, level int) bool {
+	skill := g.skill(category)
+	return skill != nil && *skill >= level
+}
+
+// timeLimit returns how long the player has to answer quest.
+func (g *Game) timeLimit(quest *Quest) time.Duration {
+	if g.skillAtLeast(quest.Category, perkTimeSkill) {
+		return quest.TimeLimit * (100 + perkTimePercent) / 100
+	}
+	return quest.TimeLimit
+}
+
+// freeHints returns how many hints of quest cost nothing.
+func (g *Game) freeHints(quest *Quest) int {
+	if g.skillAtLeast(quest.Category, perkHintSkill) {
+		return 1
+	}
+	return 0
+}
+
+// perks describes what the skills of the player give them.
+func (g *Game) perks() []string {
+	var perks []string
+	for _, category := range []QuestCategory{HackerQuest, EngineeringQuest, AstronomicalQuest, BiologicalQuest, PhysicalQuest} {
+		name := getCategoryName(category)
+		if g.skillAtLeast(category, perkTimeSkill) {
+			perks = append(perks, fmt.Sprintf("%s: +%d%% quest time", name, perkTimePercent))
+		}
+		if g.skillAtLeast(category, perkEnergySkill) {
+			perks = append(perks, fmt.Sprintf("%s: answers cost half the energy", name))
+		}
+		if g.skillAtLeast(category, perkHintSkill) {
+			perks = append(perks, fmt.Sprintf("%s: first hint free", name))
+		}
+	}
+	return perks
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLevelFor(t *testing.T) {
+	tests := []struct {
+		xp    int
+		level int
+		title string
+	}{
+		{0, 1, "Cadet"},
+		{14, 1, "Cadet"},
+		{15, 2, "Technician"},
+		{119, 4, "Engineer"},
+		{180, 6, "Quantum Architect"},
+		{1000, 6, "Quantum Architect"},
+	}
+	for _, tt := range tests {
+		level, r := levelFor(tt.xp)
+		if level != tt.level || r.Title != tt.title {
+			t.Errorf("levelFor(%d) = %d %s, want %d %s", tt.xp, level, r.Title, tt.level, tt.title)
+		}
+	}
+}
+
+func TestSkillsAreCapped(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	g.Player.Stats.Physics = maxSkill - 5
+	xp := g.Player.Stats.XP
+	g.addStat(PhysicalQuest, 20)
+	if g.Player.Stats.Physics != maxSkill {
+		t.Errorf("physics %d, want the cap of %d", g.Player.Stats.Physics, maxSkill)
+	}
+	if g.Player.Stats.XP != xp+20 {
+		t.Errorf("XP %d, want all %d gained", g.Player.Stats.XP, xp+20)
+	}
+}
+
+func TestSkillPerks(t *testing.T) {
+	tests := []struct {
+		skill     int
+		timeLimit time.Duration
+		freeHints int
+		perks     int
+	}{
+		{perkTimeSkill - 1, 4 * time.Minute, 0, 0},
+		{perkTimeSkill, 5 * time.Minute, 0, 1},
+		{perkEnergySkill, 5 * time.Minute, 0, 2},
+		{perkHintSkill, 5 * time.Minute, 1, 3},
+	}
+	for _, tt := range tests {
+		g, _ := newTestGame(t, "normal", 7)
+		g.Player.Stats.Biology = tt.skill
+		quest := &Quest{Category: BiologicalQuest, TimeLimit: 4 * time.Minute}
+		if got := g.timeLimit(quest); got != tt.timeLimit {
+			t.Errorf("skill %d: time limit %v, want %v", tt.skill, got, tt.timeLimit)
+		}
+		if got := g.freeHints(quest); got != tt.freeHints {
+			t.Errorf("skill %d: %d free hints, want %d", tt.skill, got, tt.freeHints)
+		}
+		if got := g.perks(); len(got) != tt.perks {
+			t.Errorf("skill %d: perks %q, want %d", tt.skill, got, tt.perks)
+		}
+	}
+}
+
+func TestQuestExperience(t *testing.T) {
+	tests := []struct {
+		name  string
+		hints int
+		skill int
+		fast  bool
+		want  int
+	}{
+		{"no hints", 0, 0, false, 15},
+		{"hints cost experience", 2, 0, false, 15 - 2*3*hintXPPenalty},
+		{"the free hint costs nothing", 2, perkHintSkill, false, 15 - 3*hintXPPenalty},
+		{"speed bonus", 0, 0, true, 15 + 3*speedBonusPerDifficulty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := newTestGame(t, "normal", 7)
+			quest := g.Player.Quests[0]
+			quest.Difficulty = 3
+			g.Player.HintsUsed[quest.ID] = tt.hints
+			g.Player.Stats.XP = 0
+			*g.skill(quest.Category) = tt.skill
+
+			g.completeQuest(quest, tt.fast)
+			if g.Player.Stats.XP != tt.want {
+				t.Errorf("%d XP, want %d", g.Player.Stats.XP, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelUpIsAnnounced(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	g.Player.Stats.XP = ranks[1].XP - 1
+	g.completeQuest(g.Player.Quests[0], false)
+	var out []string
+	for _, ev := range g.events {
+		out = append(out, ev.Text)
+	}
+	if !strings.Contains(strings.Join(out, "\n"), "Level up! You are now level 2: Technician.") {
+		t.Errorf("no level up in %q", out)
+	}
+}
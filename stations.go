@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Station is an interactive object in a room, such as a terminal, where
+// quests are played.
+type Station struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Quests      []int  `json:"quests"` // IDs of the quests played here
+}
+
+func (s *Station) hosts(questID int) bool {
+	for _, id := range s.Quests {
+		if id == questID {
+			return true
+		}
+	}
+	return false
+}
+
+// questLocation returns the room and station where quest is played. A
+// quest no station hosts is played in the room its requirements name, if
+// any, without a station.
+func (g *Game) questLocation(quest *Quest) (*Room, *Station) {
+	for _, room := range g.Rooms {
+		for _, station := range room.Stations {
+			if station.hosts(quest.ID) {
+				return room, station
+			}
+		}
+	}
+	for _, req := range questRequirements(quest) {
+		if req.Kind == "room" {
+			if room, ok := g.Rooms[req.Name]; ok {
+				return room, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// locationName describes where quest is played, or "" if anywhere.
+func (g *Game) locationName(quest *Quest) string {
+	room, station := g.questLocation(quest)
+	switch {
+	case station != nil:
+		return fmt.Sprintf("%s, %s", station.Name, room.Name)
+	case room != nil:
+		return room.Name
+	}
+	return ""
+}
+
+// roomQuests returns the player's quests played in room.
+func (g *Game) roomQuests(room *Room) []*Quest {
+	var quests []*Quest
+	for _, quest := range g.Player.Quests {
+		if at, _ := g.questLocation(quest); at == room {
+			quests = append(quests, quest)
+		}
+	}
+	return quests
+}
+
+// checkRoomSolved marks the room of quest, one of the player's, solved once
+// all quests there are done. The room is shared in multiplayer, so that
+// takes the quests of every player in the world.
+func (g *Game) checkRoomSolved(quest *Quest) {
+	room, _ := g.questLocation(quest)
+	if room == nil || room.Solved || !g.Player.hasQuest(quest) {
+		return
+	}
+	players := []*Game{g}
+	if g.world != nil {
+		players = players[:0]
+		for _, player := range g.world.players {
+			players = append(players, player)
+		}
+	}
+	for _, player := range players {
+		for _, q := range player.roomQuests(room) {
+			if !q.Solved {
+				return
+			}
+		}
+	}
+	room.Solved = true
+	g.message(EventSuccess, fmt.Sprintf("✨ Every quest in the %s is solved!", room.Name))
+	g.announce(room, fmt.Sprintf("%s has solved the last quest in the %s.", g.Player.Name, room.Name))
+}
+
+// stationViews describes the stations of room with the player's quests at
+// each of them.
+func (g *Game) stationViews(room *Room) []StationView {
+	views := make([]StationView, 0, len(room.Stations))
+	for _, station := range room.Stations {
+		view := StationView{Name: station.Name, Description: station.Description}
+		solved := 0
+		for _, quest := range g.Player.Quests {
+			if station.hosts(quest.ID) {
+				view.Quests = append(view.Quests, quest.ID)
+				if quest.Solved {
+					solved++
+				}
+			}
+		}
+		sort.Ints(view.Quests)
+		view.Solved = len(view.Quests) > 0 && solved == len(view.Quests)
+		views = append(views, view)
+	}
+	return views
+}
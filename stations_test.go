@@ -0,0 +1,118 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// stationQuest returns a quest of the player hosted by a station, with its
+// other requirements dropped.
+func stationQuest(t *testing.T, g *Game) (*Quest, *Room, *Station) {
+	t.Helper()
+	for _, quest := range g.Player.Quests {
+		if room, station := g.questLocation(quest); station != nil {
+			quest.Requirements = nil
+			return quest, room, station
+		}
+	}
+	t.Fatal("no quest of the player is played at a station")
+	return nil, nil, nil
+}
+
+func TestQuestsStartAtTheirStation(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	quest, room, station := stationQuest(t, g)
+	for _, other := range g.Rooms {
+		if other != room {
+			g.Player.CurrentRoom = other
+			break
+		}
+	}
+
+	out := run(g, "start "+strconv.Itoa(quest.ID))
+	if g.activeQuest != nil {
+		t.Fatal("the quest started away from its station")
+	}
+	if want := "you must be at the " + station.Name + " in the " + room.Name; !strings.Contains(out, want) {
+		t.Errorf("start said %q, want %q", out, want)
+	}
+
+	g.Player.CurrentRoom = room
+	run(g, "start "+strconv.Itoa(quest.ID))
+	if g.activeQuest != quest {
+		t.Fatal("the quest did not start at its station")
+	}
+}
+
+func TestQuestLocation(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	quest, room, station := stationQuest(t, g)
+	if got, want := g.locationName(quest), station.Name+", "+room.Name; got != want {
+		t.Errorf("location %q, want %q", got, want)
+	}
+
+	anywhere := &Quest{ID: 900}
+	inObservatory := &Quest{ID: 901, Requirements: []string{"room:observatory"}}
+	if got := g.locationName(anywhere); got != "" {
+		t.Errorf("a quest without a station or room is played at %q", got)
+	}
+	if got := g.locationName(inObservatory); got != g.Rooms["observatory"].Name {
+		t.Errorf("a quest requiring the observatory is played at %q", got)
+	}
+}
+
+func TestRoomSolved(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	_, room, _ := stationQuest(t, g)
+	quests := g.roomQuests(room)
+
+	for i, quest := range quests {
+		g.events = nil
+		g.completeQuest(quest, false)
+		out := eventText(Result{Events: g.events})
+		last := i == len(quests)-1
+		if room.Solved != last {
+			t.Errorf("after %d of %d quests the room is solved = %v", i+1, len(quests), room.Solved)
+		}
+		if strings.Contains(out, "Every quest in the") != last {
+			t.Errorf("after %d of %d quests: %q", i+1, len(quests), out)
+		}
+	}
+
+	for _, view := range g.stationViews(room) {
+		if len(view.Quests) > 0 && !view.Solved {
+			t.Errorf("station %s is not marked solved", view.Name)
+		}
+	}
+}
+
+func TestRoomSolvedOnlyByQuestsOfThePlayer(t *testing.T) {
+	g, _ := newTestGame(t, "normal", 7)
+	quest, room, _ := stationQuest(t, g)
+	g.Player.Quests = nil
+
+	g.checkRoomSolved(quest)
+	if room.Solved {
+		t.Error("a quest the player does not have solved their room")
+	}
+}
+
+func TestSharedRoomSolvedByEveryPlayer(t *testing.T) {
+	cfg, _ := testConfig(t, "normal", 42)
+	w := NewWorld(cfg)
+	alice, _ := w.Join("alice")
+	bob, _ := w.Join("bob")
+	alice.Player.Quests = []*Quest{questByID(t, alice, 1)}
+	bob.Player.Quests = []*Quest{questByID(t, bob, 1)}
+	room, _ := alice.questLocation(alice.Player.Quests[0])
+
+	alice.completeQuest(alice.Player.Quests[0], false)
+	if room.Solved {
+		t.Fatal("the room was solved while bob's quest there is open")
+	}
+	bob.completeQuest(bob.Player.Quests[0], false)
+	if !room.Solved {
+		t.Error("the room is not solved with every player's quests done")
+	}
+}
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ANSI Color codes
+const (
+	ColorReset  = "\033[0m"
+	ColorRed    = "\033[31m"
+	ColorGreen  = "\033[32m"
+	ColorYellow = "\033[33m"
+	ColorBlue   = "\033[34m"
+	ColorPurple = "\033[35m"
+	ColorCyan   = "\033[36m"
+	ColorWhite  = "\033[37m"
+	ColorBold   = "\033[1m"
+)
+
+// Renderer draws the events produced by the engine.
+type Renderer interface {
+	// Render draws the events of one command in order.
+	Render(events []Event)
+	// Prompt asks for the next line of input.
+	Prompt(p Prompt)
+}
+
+// TerminalRenderer draws events on an ANSI terminal.
+type TerminalRenderer struct {
+	out   io.Writer
+	plain bool // no colours, screen clearing, pauses or prompts
+}
+
+// NewTerminalRenderer creates a renderer writing to out.
+func NewTerminalRenderer(out io.Writer) *TerminalRenderer {
+	return &TerminalRenderer{out: out}
+}
+
+// NewPlainRenderer creates a renderer writing plain text to out, for
+// scripts and logs.
+func NewPlainRenderer(out io.Writer) *TerminalRenderer {
+	return &TerminalRenderer{out: ansiStripper{out}, plain: true}
+}
+
+var ansiSequence = regexp.MustCompile("\033\\[[0-9;]*[A-Za-z]")
+
+// ansiStripper drops ANSI escape sequences from everything written to it.
+// Every write holds whole sequences, so they never need to be joined.
+type ansiStripper struct {
+	w io.Writer
+}
+
+func (s ansiStripper) Write(p []byte) (int, error) {
+	if _, err := s.w.Write(ansiSequence.ReplaceAll(p, nil)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// UI Helper functions
+func (t *TerminalRenderer) clearScreen() {
+	if t.plain {
+		return
+	}
+	fmt.Fprint(t.out, "\033[2J\033[H")
+}
+
+func (t *TerminalRenderer) printBanner() {
+	banner := `
+╔══════════════════════════════════════════════════════════════╗
+║                                                              ║
+║  ██████╗ ██████╗ ███████╗███╗   ███╗██╗   ██╗██████╗ ██████╗ ║
+║ ██╔════╝██╔═══██╗██╔════╝████╗ ████║██║   ██║██╔══██╗██╔══██╗║
+║ ██║     ██║   ██║███████╗██╔████╔██║██║   ██║██████╔╝██████╔╝║
+║ ██║     ██║   ██║╚════██║██║╚██╔╝██║██║   ██║██╔═══╝ ██╔══██╗║
+║ ╚██████╗╚██████╔╝███████║██║ ╚═╝ ██║╚██████╔╝██║     ██║  ██║║
+║  ╚═════╝ ╚═════╝ ╚══════╝╚═╝     ╚═╝ ╚═════╝ ╚═╝     ╚═╝  ╚═╝║
+║                                                              ║
+║              🌌 COSMIC CYBERPUNK ROOM ESCAPE 🌌             ║
+║                                                              ║
+╚══════════════════════════════════════════════════════════════╝`
+	fmt.Fprintf(t.out, "%s%s\n", ColorCyan, banner)
+}
+
+func (t *TerminalRenderer) printSeparator() {
+	fmt.Fprintf(t.out, "%s%s%s\n", ColorBlue, strings.Repeat("═", 70), ColorReset)
+}
+
+func (t *TerminalRenderer) printColored(text, color string) {
+	fmt.Fprintf(t.out, "%s%s%s", color, text, ColorReset)
+}
+
+func (t *TerminalRenderer) printSuccess(message string) {
+	fmt.Fprintf(t.out, "%s✅ %s%s\n", ColorGreen, message, ColorReset)
+}
+
+func (t *TerminalRenderer) printWarning(message string) {
+	fmt.Fprintf(t.out, "%s⚠️  %s%s\n", ColorYellow, message, ColorReset)
+}
+
+func (t *TerminalRenderer) printError(message string) {
+	fmt.Fprintf(t.out, "%s❌ %s%s\n", ColorRed, message, ColorReset)
+}
+
+func (t *TerminalRenderer) printInfo(message string) {
+	fmt.Fprintf(t.out, "%sℹ️  %s%s\n", ColorCyan, message, ColorReset)
+}
+
+func (t *TerminalRenderer) printASCII(ascii string) {
+	fmt.Fprintf(t.out, "%s%s%s\n", ColorPurple, ascii, ColorReset)
+}
+
+func (t *TerminalRenderer) printItem(item ItemView) {
+	fmt.Fprintf(t.out, "  • ")
+	t.printColored(item.Name, ColorCyan)
+	fmt.Fprintf(t.out, " - %s", item.Description)
+	if item.Energy > 0 {
+		fmt.Fprintf(t.out, " (🔋 +%d energy)", item.Energy)
+	}
+	fmt.Fprintln(t.out)
+	t.printASCII(item.ASCII)
+}
+
+// Prompt implements Renderer.
+func (t *TerminalRenderer) Prompt(p Prompt) {
+	if t.plain {
+		return
+	}
+	switch p.Mode {
+	case PromptAnswer:
+		if p.QuestTimeLeft == 0 {
+			fmt.Fprint(t.out, "> ")
+			return
+		}
+		left := p.QuestTimeLeft.Round(time.Second)
+		fmt.Fprintf(t.out, "%s[⏳ %d:%02d]%s > ", ColorYellow, int(left.Minutes()), int(left.Seconds())%60, ColorReset)
+	case PromptContinue:
+		t.printSeparator()
+		t.printInfo("Press Enter to continue...")
+	default:
+		fmt.Fprint(t.out, "\n🎮 > ")
+	}
+}
+
+// Render implements Renderer.
+func (t *TerminalRenderer) Render(events []Event) {
+	for _, ev := range events {
+		t.render(ev)
+	}
+}
+
+func (t *TerminalRenderer) render(ev Event) {
+	switch ev.Kind {
+	case EventClear:
+		t.clearScreen()
+	case EventBanner:
+		t.printBanner()
+	case EventText:
+		fmt.Fprintln(t.out, ev.Text)
+	case EventInfo:
+		t.printInfo(ev.Text)
+	case EventSuccess:
+		t.printSuccess(ev.Text)
+	case EventWarning:
+		t.printWarning(ev.Text)
+	case EventError:
+		t.printError(ev.Text)
+	case EventPause:
+		if !t.plain {
+			time.Sleep(ev.Delay)
+		}
+	case EventRoom:
+		t.renderRoom(ev.Room)
+	case EventInventory:
+		t.renderInventory(ev.Items)
+	case EventQuests:
+		t.renderQuests(ev.Quests)
+	case EventQuest:
+		t.renderQuest(ev.Quest)
+	case EventHints:
+		t.renderHints(ev.Quest)
+	case EventStats:
+		t.renderStats(ev.Stats)
+	case EventHelp:
+		t.renderHelp(ev.Commands)
+	case EventMap:
+		t.renderMap(ev.Map)
+	case EventEnd:
+		t.renderEnd(ev.End)
+	case EventLeaderboard:
+		t.renderLeaderboard(ev.Text, ev.Runs)
+	}
+}
+
+func (t *TerminalRenderer) renderRoom(room *RoomView) {
+	// Print room ASCII art
+	t.printASCII(room.ASCII)
+
+	t.printSeparator()
+	t.printColored(fmt.Sprintf("📍 %s", room.Name), ColorBold+ColorYellow)
+	if room.Solved {
+		fmt.Fprint(t.out, " ✅ solved")
+	}
+	fmt.Fprintln(t.out)
+	t.printSeparator()
+
+	t.printColored(room.Description, ColorWhite)
+	fmt.Fprintln(t.out)
+
+	// Show player stats
+	stats := room.Stats
+	fmt.Fprintln(t.out)
+	t.printColored("📊 YOUR STATS:", ColorBold+ColorCyan)
+	fmt.Fprintf(t.out, "  💻 Hacking: %d/100    ⚙️ Engineering: %d/100\n", stats.Hacking, stats.Engineering)
+	fmt.Fprintf(t.out, "  ⭐ Astronomy: %d/100  🧬 Biology: %d/100\n", stats.Astronomy, stats.Biology)
+	fmt.Fprintf(t.out, "  ⚡ Physics: %d/100    🔋 Energy: %d/100\n", stats.Physics, stats.Energy)
+	fmt.Fprintf(t.out, "  ⏰ Time Left: %s\n", stats.TimeLeft.Round(time.Second))
+	if room.Warning != "" {
+		t.printWarning(room.Warning)
+	}
+
+	if len(room.Players) > 0 {
+		fmt.Fprintln(t.out)
+		t.printColored("👥 Also here: ", ColorGreen)
+		fmt.Fprintln(t.out, strings.Join(room.Players, ", "))
+	}
+
+	if len(room.Items) > 0 {
+		fmt.Fprintln(t.out)
+		t.printColored("🔍 You can see:", ColorGreen)
+		for _, item := range room.Items {
+			t.printItem(item)
+		}
+	}
+
+	if len(room.Stations) > 0 {
+		fmt.Fprintln(t.out)
+		t.printColored("🖥️ Stations:", ColorCyan)
+		for _, station := range room.Stations {
+			fmt.Fprintf(t.out, "  • ")
+			t.printColored(station.Name, ColorCyan)
+			fmt.Fprintf(t.out, " - %s", station.Description)
+			if len(station.Quests) > 0 {
+				ids := make([]string, len(station.Quests))
+				for i, id := range station.Quests {
+					ids[i] = fmt.Sprint(id)
+				}
+				fmt.Fprintf(t.out, " (quests: %s)", strings.Join(ids, ", "))
+			}
+			if station.Solved {
+				fmt.Fprint(t.out, " ✅")
+			}
+			fmt.Fprintln(t.out)
+		}
+	}
+
+	fmt.Fprintln(t.out)
+	t.printColored("🚪 Exits:", ColorBlue)
+	for _, exit := range room.Exits {
+		fmt.Fprintf(t.out, "  • ")
+		t.printColored(exit.Direction, ColorPurple)
+		if exit.Locked {
+			fmt.Fprintf(t.out, " 🔒")
+		}
+		fmt.Fprintln(t.out)
+	}
+	t.printSeparator()
+}
+
+func (t *TerminalRenderer) renderEnd(end *EndView) {
+	fmt.Fprintln(t.out)
+	t.printSeparator()
+	if end.Outcome == OutcomeWon.String() {
+		t.printColored("🚀 YOU ESCAPED!", ColorBold+ColorGreen)
+	} else {
+		t.printColored("💀 GAME OVER", ColorBold+ColorRed)
+	}
+	fmt.Fprintln(t.out)
+	t.printSeparator()
+	fmt.Fprintf(t.out, "🏆 Score: %d\n", end.Score)
+	fmt.Fprintf(t.out, "⏱️ Time taken: %s\n", end.TimeTaken.Round(time.Second))
+	fmt.Fprintf(t.out, "💡 Hints used: %d    ❎ Wrong answers: %d\n", end.HintsUsed, end.WrongAttempts)
+	fmt.Fprintf(t.out, "🔋 Energy left: %d/100\n", end.EnergyLeft)
+	fmt.Fprintf(t.out, "🎯 Quests completed: %d/%d\n", end.Completed, end.Total)
+	fmt.Fprintf(t.out, "🏅 Level: %d (%s)\n", end.Level, end.Rank)
+	fmt.Fprintf(t.out, "🎚️ Mode: %s    🎲 Seed: %d\n", end.Mode, end.Seed)
+	t.printSeparator()
+}
+
+func (t *TerminalRenderer) renderLeaderboard(filter string, runs []LeaderboardEntry) {
+	title := "🏆 LEADERBOARD"
+	if filter != "" {
+		title += " (" + filter + ")"
+	}
+	t.printColored(title, ColorBold+ColorYellow)
+	t.printSeparator()
+	if len(runs) == 0 {
+		t.printWarning("No runs recorded yet.")
+		return
+	}
+	fmt.Fprintf(t.out, "%3s  %-16s %7s  %-9s %-8s %10s  %s\n", "#", "Name", "Score", "Mode", "Result", "Time", "Seed")
+	for i, run := range runs {
+		fmt.Fprintf(t.out, "%3d  %-16s %7d  %-9s %-8s %10s  %d\n", i+1, run.Name, run.Score, run.Mode, run.Outcome, run.Duration.Round(time.Second), run.Seed)
+	}
+	t.printSeparator()
+}
+
+func (t *TerminalRenderer) renderMap(rooms []MapRoomView) {
+	t.printColored("🗺️ STATION MAP", ColorBold+ColorYellow)
+	t.printSeparator()
+	for _, room := range rooms {
+		name := room.Name
+		if room.Here {
+			name += " (you are here)"
+		}
+		t.printColored("📍 "+name, ColorYellow)
+		fmt.Fprintln(t.out)
+		if len(room.Requires) > 0 {
+			fmt.Fprintf(t.out, "   Needs: %s\n", strings.Join(room.Requires, " or "))
+		}
+		for _, exit := range room.Exits {
+			fmt.Fprintf(t.out, "   • %s → %s", exit.Direction, exit.To)
+			if exit.LockedBy != "" {
+				fmt.Fprintf(t.out, " 🔒 %s", exit.LockedBy)
+			}
+			fmt.Fprintln(t.out)
+		}
+	}
+	t.printSeparator()
+}
+
+func (t *TerminalRenderer) renderInventory(items []ItemView) {
+	t.printColored("🎒 YOUR INVENTORY", ColorBold+ColorYellow)
+	t.printSeparator()
+
+	if len(items) == 0 {
+		t.printWarning("Your inventory is empty.")
+		return
+	}
+
+	for _, item := range items {
+		t.printItem(item)
+	}
+	t.printSeparator()
+}
+
+func (t *TerminalRenderer) renderQuests(quests []QuestView) {
+	t.printColored("🎯 YOUR QUESTS", ColorBold+ColorYellow)
+	t.printSeparator()
+
+	if len(quests) == 0 {
+		t.printWarning("No active quests!")
+		return
+	}
+
+	var available []string
+	for i, quest := range quests {
+		status := "❌"
+		switch {
+		case quest.Solved:
+			status = "✅"
+		case len(quest.Locked) > 0:
+			status = "🔒"
+		default:
+			available = append(available, fmt.Sprint(quest.ID))
+		}
+
+		fmt.Fprintf(t.out, "%s %s %s (ID: %d) - %s\n", status, getCategoryEmoji(quest.Category), quest.Name, quest.ID, getCategoryName(quest.Category))
+		fmt.Fprintf(t.out, "   Difficulty: %d/5 ⭐\n", quest.Difficulty)
+		fmt.Fprintf(t.out, "   Time Limit: %s\n", quest.TimeLimit.Round(time.Second))
+		fmt.Fprintf(t.out, "   Energy per answer: %d\n", quest.EnergyCost)
+		fmt.Fprintf(t.out, "   Reward: %s\n", quest.Reward)
+		if quest.Location != "" {
+			fmt.Fprintf(t.out, "   Location: %s\n", quest.Location)
+		}
+		fmt.Fprintf(t.out, "   Description: %s\n", quest.Description)
+		if quest.HintCount > 0 {
+			fmt.Fprintf(t.out, "   Hints used: %d/%d\n", quest.HintsUsed, quest.HintCount)
+		}
+		if quest.Coop != nil {
+			fmt.Fprintf(t.out, "   🤝 Co-op: %d players, %s to enter every part\n", quest.Coop.Players, quest.Coop.Window.Round(time.Second))
+		}
+		if len(quest.Locked) > 0 {
+			fmt.Fprintf(t.out, "   %sLocked: %s%s\n", ColorYellow, strings.Join(quest.Locked, "; "), ColorReset)
+		}
+
+		if !quest.Solved {
+			t.printASCII(quest.ASCII)
+		}
+
+		if i < len(quests)-1 {
+			fmt.Fprintln(t.out)
+		}
+	}
+
+	if len(available) > 0 {
+		t.printInfo("Available quest IDs: ")
+		fmt.Fprintln(t.out, strings.Join(available, " "))
+	}
+}
+
+func (t *TerminalRenderer) renderQuest(quest *QuestView) {
+	t.printColored(fmt.Sprintf("🎯 STARTING QUEST: %s", quest.Name), ColorBold+ColorYellow)
+	t.printSeparator()
+
+	fmt.Fprintf(t.out, "%s Category: %s\n", getCategoryEmoji(quest.Category), getCategoryName(quest.Category))
+	fmt.Fprintf(t.out, "⭐ Difficulty: %d/5\n", quest.Difficulty)
+	fmt.Fprintf(t.out, "⏰ Time Limit: %s\n", quest.TimeLimit.Round(time.Second))
+	fmt.Fprintf(t.out, "🔋 Energy per answer: %d\n", quest.EnergyCost)
+	fmt.Fprintf(t.out, "🎁 Reward: %s\n", quest.Reward)
+	fmt.Fprintln(t.out)
+	t.printColored("Description:", ColorCyan)
+	fmt.Fprintln(t.out, quest.Description)
+	fmt.Fprintln(t.out)
+
+	t.printASCII(quest.ASCII)
+
+	if coop := quest.Coop; coop != nil {
+		fmt.Fprintln(t.out)
+		t.printColored(fmt.Sprintf("🤝 CO-OP QUEST: %d players must each enter one part within %s.", coop.Players, coop.Window.Round(time.Second)), ColorCyan)
+		for i, size := range coop.PartSizes {
+			who := "open"
+			if i < len(coop.Claimed) && coop.Claimed[i] != "" {
+				who = "entered by " + coop.Claimed[i]
+			}
+			fmt.Fprintf(t.out, "   Part %d: %d piece(s) of the answer - %s\n", i+1, size, who)
+		}
+	}
+
+	fmt.Fprintln(t.out)
+	t.printColored("Enter your solution:", ColorGreen)
+	fmt.Fprintln(t.out)
+}
+
+func (t *TerminalRenderer) renderHints(quest *QuestView) {
+	t.printColored(fmt.Sprintf("💡 HINTS FOR: %s", quest.Name), ColorBold+ColorYellow)
+	t.printSeparator()
+
+	fmt.Fprintf(t.out, "%s Category: %s\n", getCategoryEmoji(quest.Category), getCategoryName(quest.Category))
+	fmt.Fprintf(t.out, "⭐ Difficulty: %d/5\n", quest.Difficulty)
+	fmt.Fprintln(t.out)
+
+	t.printColored("Description:", ColorCyan)
+	fmt.Fprintln(t.out, quest.Description)
+	fmt.Fprintln(t.out)
+
+	t.printASCII(quest.ASCII)
+	fmt.Fprintln(t.out)
+
+	t.printColored(fmt.Sprintf("💡 HINTS (%d/%d revealed):", quest.HintsUsed, quest.HintCount), ColorGreen)
+	if len(quest.Hints) == 0 {
+		fmt.Fprintln(t.out, "No hints revealed yet.")
+	}
+	for i, hint := range quest.Hints {
+		fmt.Fprintf(t.out, "%s\n", hint)
+		if i < len(quest.Hints)-1 {
+			fmt.Fprintln(t.out)
+		}
+	}
+
+	if quest.Example != "" {
+		fmt.Fprintln(t.out)
+		t.printColored("Example:", ColorCyan)
+		fmt.Fprintln(t.out, quest.Example)
+	}
+}
+
+func (t *TerminalRenderer) renderStats(stats *StatsView) {
+	t.printColored("📊 DETAILED STATS", ColorBold+ColorYellow)
+	t.printSeparator()
+
+	if stats.NextLevelXP > 0 {
+		fmt.Fprintf(t.out, "🏅 Level %d - %s (%d/%d XP)\n", stats.Level, stats.Rank, stats.XP, stats.NextLevelXP)
+	} else {
+		fmt.Fprintf(t.out, "🏅 Level %d - %s (%d XP, top rank)\n", stats.Level, stats.Rank, stats.XP)
+	}
+	fmt.Fprintf(t.out, "🏆 Score so far: %d\n", stats.Score)
+
+	fmt.Fprintf(t.out, "💻 Hacking: %d/100\n", stats.Hacking)
+	fmt.Fprintf(t.out, "⚙️ Engineering: %d/100\n", stats.Engineering)
+	fmt.Fprintf(t.out, "⭐ Astronomy: %d/100\n", stats.Astronomy)
+	fmt.Fprintf(t.out, "🧬 Biology: %d/100\n", stats.Biology)
+	fmt.Fprintf(t.out, "⚡ Physics: %d/100\n", stats.Physics)
+	fmt.Fprintf(t.out, "🔋 Energy: %d/100\n", stats.Energy)
+	fmt.Fprintf(t.out, "⏰ Time Left: %s\n", stats.TimeLeft.Round(time.Second))
+	fmt.Fprintf(t.out, "✅ Quests Completed: %d/%d\n", stats.Completed, stats.Total)
+	fmt.Fprintf(t.out, "🎲 Seed: %d\n", stats.Seed)
+	fmt.Fprintf(t.out, "🎚️ Mode: %s\n", stats.Mode)
+	if stats.CoopSolved > 0 {
+		fmt.Fprintf(t.out, "🤝 Co-op Quests: %d\n", stats.CoopSolved)
+	}
+	if len(stats.Perks) > 0 {
+		fmt.Fprintln(t.out)
+		t.printColored("✨ Skill perks:", ColorGreen)
+		for _, perk := range stats.Perks {
+			fmt.Fprintf(t.out, "  • %s\n", perk)
+		}
+	}
+}
+
+func (t *TerminalRenderer) renderHelp(commands []CommandHelp) {
+	t.printColored("❓ GAME HELP", ColorBold+ColorYellow)
+	t.printSeparator()
+	fmt.Fprintln(t.out, "Available commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(t.out, "  %s - %s\n", ColorCyan+cmd.Usage+ColorReset, cmd.Text)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// screens plays commands that bring up every kind of screen and returns
+// their events.
+func screens(t *testing.T) []Event {
+	t.Helper()
+	g, _ := newTestGame(t, "normal", 7)
+	quest := g.Player.Quests[0]
+	var events []Event
+	for _, command := range []string{"look", "inventory", "quests", "", "stats", "", "help", "", "hints " + strconv.Itoa(quest.ID), "", "leaderboard", "", "go nowhere", "quit"} {
+		events = append(events, g.ProcessCommand(command).Events...)
+	}
+	return events
+}
+
+func TestTerminalRenderer(t *testing.T) {
+	var out bytes.Buffer
+	r := NewTerminalRenderer(&out)
+	var events []Event
+	for _, ev := range screens(t) {
+		if ev.Kind != EventPause {
+			events = append(events, ev)
+		}
+	}
+	r.Render(events)
+	r.Prompt(Prompt{Mode: PromptCommand})
+
+	text := out.String()
+	for _, want := range []string{"\033[2J", ColorGreen, "Thanks for playing!", "🎮 > "} {
+		if !strings.Contains(text, want) {
+			t.Errorf("terminal output lacks %q", want)
+		}
+	}
+}
+
+func TestPlainRenderer(t *testing.T) {
+	var out bytes.Buffer
+	r := NewPlainRenderer(&out)
+	r.Render(screens(t))
+	for _, mode := range []PromptMode{PromptCommand, PromptAnswer, PromptContinue} {
+		r.Prompt(Prompt{Mode: mode})
+	}
+
+	text := out.String()
+	if strings.Contains(text, "\033[") {
+		t.Error("plain output has ANSI escape sequences")
+	}
+	if strings.Contains(text, "🎮 > ") || strings.Contains(text, "Press Enter") {
+		t.Error("plain output has prompts")
+	}
+	if !strings.Contains(text, "Thanks for playing!") {
+		t.Errorf("plain output lacks the game's text: %q", text)
+	}
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Quest packs may also be written in TOML, one [[quest]] table per quest.
+// Strings of every kind, integers, floats, booleans, arrays, inline tables,
+// dotted keys and sub-tables such as [quest.coop] are read; dates are not,
+// as no quest field holds one.
+
+type tomlParser struct {
+	data    string
+	pos     int
+	root    map[string]interface{}
+	table   map[string]interface{} // the table keys are added to
+	inQuest bool                   // a [[quest]] table has started
+	quests  []int                  // the line of every [[quest]] header
+}
+
+// parseTOMLQuests reads a TOML file holding [[quest]] tables.
+func parseTOMLQuests(data []byte) ([]rawQuest, error) {
+	p := &tomlParser{data: string(data), root: map[string]interface{}{}}
+	p.table = p.root
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.data) {
+			break
+		}
+		var err error
+		if p.data[p.pos] == '[' {
+			err = p.parseHeader()
+		} else if !p.inQuest {
+			err = p.errorf("every quest starts with a [[quest]] header")
+		} else {
+			err = p.parseKeyValue(p.table)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+
+	list, _ := p.root["quest"].([]interface{})
+	quests := make([]rawQuest, len(list))
+	for i, fields := range list {
+		quests[i] = rawQuest{line: p.quests[i], fields: fields.(map[string]interface{})}
+	}
+	return quests, nil
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return lineError(strings.Count(p.data[:p.pos], "\n")+1, format, args...)
+}
+
+// skipSpace skips spaces and tabs.
+func (p *tomlParser) skipSpace() {
+	for p.pos < len(p.data) && (p.data[p.pos] == ' ' || p.data[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipComment skips a comment up to the end of its line.
+func (p *tomlParser) skipComment() {
+	if p.pos < len(p.data) && p.data[p.pos] == '#' {
+		for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+			p.pos++
+		}
+	}
+}
+
+// skipBlank skips whitespace, line breaks and comments.
+func (p *tomlParser) skipBlank() {
+	for {
+		p.skipSpace()
+		p.skipComment()
+		if p.pos >= len(p.data) || (p.data[p.pos] != '\n' && p.data[p.pos] != '\r') {
+			return
+		}
+		p.pos++
+	}
+}
+
+// endOfLine reads the rest of a line, which may only hold a comment.
+func (p *tomlParser) endOfLine() error {
+	p.skipSpace()
+	p.skipComment()
+	rest := p.data[p.pos:]
+	switch {
+	case rest == "":
+	case strings.HasPrefix(rest, "\n"):
+		p.pos++
+	case strings.HasPrefix(rest, "\r\n"):
+		p.pos += 2
+	default:
+		line, _, _ := strings.Cut(rest, "\n")
+		return p.errorf("unexpected %q", line)
+	}
+	return nil
+}
+
+// parseHeader reads a [table] or [[array of tables]] header.
+func (p *tomlParser) parseHeader() error {
+	closing := "]"
+	if strings.HasPrefix(p.data[p.pos:], "[[") {
+		closing = "]]"
+	}
+	p.pos += len(closing)
+	p.skipSpace()
+	path, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(p.data[p.pos:], closing) {
+		return p.errorf("expected %q", closing)
+	}
+	name := strings.Join(path, ".")
+	if path[0] != "quest" || (closing == "]" && len(path) == 1) {
+		return p.errorf("unexpected table %q: every quest is a [[quest]] table", name)
+	}
+
+	parent, err := p.walk(p.root, path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	last := path[len(path)-1]
+	table := map[string]interface{}{}
+	if closing == "]]" {
+		list, ok := parent[last].([]interface{})
+		if parent[last] != nil && !ok {
+			return p.errorf("%s is not an array of tables", name)
+		}
+		parent[last] = append(list, table)
+		if len(path) == 1 {
+			p.quests = append(p.quests, strings.Count(p.data[:p.pos], "\n")+1)
+			p.inQuest = true
+		}
+	} else {
+		if _, ok := parent[last]; ok {
+			return p.errorf("table %s is defined twice", name)
+		}
+		parent[last] = table
+	}
+	p.table = table
+	p.pos += len(closing)
+	return nil
+}
+
+// walk returns the table at path below table, creating the tables that do
+// not exist yet. An array of tables continues with its last table.
+func (p *tomlParser) walk(table map[string]interface{}, path []string) (map[string]interface{}, error) {
+	for _, key := range path {
+		switch v := table[key].(type) {
+		case nil:
+			next := map[string]interface{}{}
+			table[key] = next
+			table = next
+		case map[string]interface{}:
+			table = v
+		case []interface{}:
+			var last map[string]interface{}
+			if len(v) > 0 {
+				last, _ = v[len(v)-1].(map[string]interface{})
+			}
+			if last == nil {
+				return nil, p.errorf("%s is not a table", key)
+			}
+			table = last
+		default:
+			return nil, p.errorf("%s is not a table", key)
+		}
+	}
+	return table, nil
+}
+
+// parseKey reads a key, which may be dotted.
+func (p *tomlParser) parseKey() ([]string, error) {
+	var path []string
+	for {
+		p.skipSpace()
+		var key string
+		var err error
+		switch {
+		case p.pos >= len(p.data):
+			return nil, p.errorf("expected a key")
+		case p.data[p.pos] == '"':
+			key, err = p.parseBasicString()
+		case p.data[p.pos] == '\'':
+			key, err = p.parseLiteralString()
+		default:
+			start := p.pos
+			for p.pos < len(p.data) && isTOMLBareKey(p.data[p.pos]) {
+				p.pos++
+			}
+			if key = p.data[start:p.pos]; key == "" {
+				return nil, p.errorf("expected a key")
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, key)
+		p.skipSpace()
+		if p.pos >= len(p.data) || p.data[p.pos] != '.' {
+			return path, nil
+		}
+		p.pos++
+	}
+}
+
+func isTOMLBareKey(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == '-'
+}
+
+// parseKeyValue reads a "key = value" pair into table.
+func (p *tomlParser) parseKeyValue(table map[string]interface{}) error {
+	path, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	if p.pos >= len(p.data) || p.data[p.pos] != '=' {
+		return p.errorf("expected \"=\" after %s", strings.Join(path, "."))
+	}
+	p.pos++
+	p.skipSpace()
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	parent, err := p.walk(table, path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	last := path[len(path)-1]
+	if _, dup := parent[last]; dup {
+		return p.errorf("duplicate key %q", strings.Join(path, "."))
+	}
+	parent[last] = value
+	return nil
+}
+
+// parseValue reads a string, number, boolean, array or inline table.
+func (p *tomlParser) parseValue() (interface{}, error) {
+	rest := p.data[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, `"""`), strings.HasPrefix(rest, "'''"):
+		return p.parseMultilineString()
+	case strings.HasPrefix(rest, `"`):
+		return p.parseBasicString()
+	case strings.HasPrefix(rest, "'"):
+		return p.parseLiteralString()
+	case strings.HasPrefix(rest, "["):
+		return p.parseArray()
+	case strings.HasPrefix(rest, "{"):
+		return p.parseInlineTable()
+	}
+
+	// Booleans and numbers run up to the next separator
+	end := strings.IndexAny(rest, " \t\r\n,]}#")
+	if end < 0 {
+		end = len(rest)
+	}
+	token := rest[:end]
+	if token == "" {
+		return nil, p.errorf("expected a value")
+	}
+	var value interface{}
+	if token == "true" || token == "false" {
+		value = token == "true"
+	} else if n, err := strconv.ParseInt(strings.ReplaceAll(token, "_", ""), 0, 64); err == nil {
+		value = n
+	} else if f, err := strconv.ParseFloat(strings.ReplaceAll(token, "_", ""), 64); err == nil {
+		value = f
+	} else {
+		return nil, p.errorf("invalid value %q", token)
+	}
+	p.pos += end
+	return value, nil
+}
+
+// parseBasicString reads a "string" with escapes.
+func (p *tomlParser) parseBasicString() (string, error) {
+	for end := p.pos + 1; end < len(p.data) && p.data[end] != '\n'; end++ {
+		switch p.data[end] {
+		case '\\':
+			end++
+		case '"':
+			s, err := tomlUnescape(p.data[p.pos+1:end], false)
+			if err != nil {
+				return "", p.errorf("%v", err)
+			}
+			p.pos = end + 1
+			return s, nil
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// parseLiteralString reads a 'string' without escapes.
+func (p *tomlParser) parseLiteralString() (string, error) {
+	end := strings.IndexAny(p.data[p.pos+1:], "'\n")
+	if end < 0 || p.data[p.pos+1+end] != '\'' {
+		return "", p.errorf("unterminated string")
+	}
+	s := p.data[p.pos+1 : p.pos+1+end]
+	p.pos += end + 2
+	return s, nil
+}
+
+// parseMultilineString reads a string in triple quotes, with escapes in
+// double quotes and without in single ones. A line break right after the
+// opening quotes is dropped.
+func (p *tomlParser) parseMultilineString() (string, error) {
+	delim := p.data[p.pos : p.pos+3]
+	start := p.pos + 3
+	end := strings.Index(p.data[start:], delim)
+	if end < 0 {
+		return "", p.errorf("unterminated string")
+	}
+	s := p.data[start : start+end]
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "\r"), "\n")
+	if delim == `"""` {
+		var err error
+		if s, err = tomlUnescape(s, true); err != nil {
+			return "", p.errorf("%v", err)
+		}
+	}
+	p.pos = start + end + 3
+	return s, nil
+}
+
+// tomlUnescape replaces the escape sequences of a basic string. In a
+// multi-line string a backslash at the end of a line also removes the line
+// break and the whitespace after it.
+func tomlUnescape(s string, multiline bool) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i >= len(s) {
+			return "", fmt.Errorf("string ends with a backslash")
+		}
+		switch c := s[i]; c {
+		case 'b':
+			b.WriteByte('\b')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case '"', '\\':
+			b.WriteByte(c)
+		case 'u', 'U':
+			n := 4
+			if c == 'U' {
+				n = 8
+			}
+			if i+n >= len(s) {
+				return "", fmt.Errorf("invalid escape \\%s", s[i:])
+			}
+			r, err := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(r)) {
+				return "", fmt.Errorf("invalid escape \\%s", s[i:i+1+n])
+			}
+			b.WriteRune(rune(r))
+			i += n
+		default:
+			rest := strings.TrimLeft(s[i:], " \t\r")
+			if !multiline || !strings.HasPrefix(rest, "\n") {
+				return "", fmt.Errorf("invalid escape \\%c", c)
+			}
+			rest = strings.TrimLeft(rest, " \t\r\n")
+			i = len(s) - len(rest) - 1
+		}
+	}
+	return b.String(), nil
+}
+
+// parseArray reads an array, which may span several lines.
+func (p *tomlParser) parseArray() ([]interface{}, error) {
+	p.pos++
+	items := []interface{}{}
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.data[p.pos] == ']' {
+			p.pos++
+			return items, nil
+		}
+		item, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		p.skipBlank()
+		switch {
+		case p.pos >= len(p.data):
+			return nil, p.errorf("unterminated array")
+		case p.data[p.pos] == ',':
+			p.pos++
+		case p.data[p.pos] != ']':
+			return nil, p.errorf("expected \",\" or \"]\" in an array")
+		}
+	}
+}
+
+// parseInlineTable reads a { key = value, ... } table on one line.
+func (p *tomlParser) parseInlineTable() (map[string]interface{}, error) {
+	p.pos++
+	table := map[string]interface{}{}
+	p.skipSpace()
+	if strings.HasPrefix(p.data[p.pos:], "}") {
+		p.pos++
+		return table, nil
+	}
+	for {
+		if err := p.parseKeyValue(table); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		switch {
+		case strings.HasPrefix(p.data[p.pos:], ","):
+			p.pos++
+		case strings.HasPrefix(p.data[p.pos:], "}"):
+			p.pos++
+			return table, nil
+		default:
+			return nil, p.errorf("expected \",\" or \"}\" in an inline table")
+		}
+	}
+}
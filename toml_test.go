@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTOMLQuests(t *testing.T) {
+	tests := []struct {
+		name string
+		toml string
+		want map[string]interface{}
+	}{
+		{"values", "[[quest]]\nid = 1_000\nname = 'C:\\path'\nok = true\ntolerance = 0.5\n", map[string]interface{}{"id": int64(1000), "name": `C:\path`, "ok": true, "tolerance": 0.5}},
+		{"escapes", "[[quest]]\nexample = \"a\\tb \\u00e9\"\n", map[string]interface{}{"example": "a\tb é"}},
+		{"multi-line strings", "[[quest]]\na = \"\"\"\none \\\n  two\"\"\"\nb = '''\nraw \\n'''\n", map[string]interface{}{"a": "one two", "b": "raw \\n"}},
+		{"dotted keys and sub-tables", "[[quest]]\ncoop.players = 2\n[quest.match]\nregex = \"x\"\n", map[string]interface{}{"coop": map[string]interface{}{"players": int64(2)}, "match": map[string]interface{}{"regex": "x"}}},
+		{"arrays over several lines", "[[quest]]\nhints = [\n  \"a\", # first\n  \"b\",\n]\n", map[string]interface{}{"hints": []interface{}{"a", "b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quests, err := parseTOMLQuests([]byte(tt.toml))
+			if err != nil {
+				t.Fatalf("parseTOMLQuests: %v", err)
+			}
+			if len(quests) != 1 || quests[0].line != 1 || !reflect.DeepEqual(quests[0].fields, tt.want) {
+				t.Errorf("got %+v, want %v at line 1", quests, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTOMLQuestsErrors(t *testing.T) {
+	tests := []struct {
+		toml string
+		want string
+	}{
+		{"[quest]\n", `1: unexpected table "quest"`},
+		{"[[quest]]\nid = 1\nid = 2\n", `3: duplicate key "id"`},
+		{"[[quest]]\nid = 1 2\n", `2: unexpected "2"`},
+		{"[[quest]]\nwhen = 2024-01-01\n", `2: invalid value "2024-01-01"`},
+		{"[[quest]]\nhints = [\"a\"\n", "3: unterminated array"},
+		{"[[quest]]\nname = \"\\q\"\n", `2: invalid escape \q`},
+		{"[[quest]]\n[quest.coop]\n[quest.coop]\n", "3: table quest.coop is defined twice"},
+	}
+	for _, tt := range tests {
+		_, err := parseTOMLQuests([]byte(tt.toml))
+		var loadErr LoadError
+		if !errors.As(err, &loadErr) || !strings.HasPrefix(fmt.Sprintf("%d: %s", loadErr.Line, loadErr.Msg), tt.want) {
+			t.Errorf("%q: error %v, want %q", tt.toml, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// transcriptVersion is the format written by Recorder.
+const transcriptVersion = 1
+
+// transcriptHeader is the first line of a transcript. It holds everything
+// needed to start the same game again.
+type transcriptHeader struct {
+	Version    int       `json:"version"`
+	Seed       int64     `json:"seed"`
+	Level      string    `json:"level,omitempty"`
+	Quests     string    `json:"quests,omitempty"`
+	Mode       string    `json:"mode,omitempty"`
+	PauseMenus bool      `json:"pause_menus"`
+	Start      time.Time `json:"start"`
+}
+
+// transcriptEntry is one step of a recorded game: a command, or a clock tick
+// that changed the game, with the state it left behind.
+type transcriptEntry struct {
+	Time  time.Time `json:"time"`
+	Input string    `json:"input,omitempty"`
+	Tick  bool      `json:"tick,omitempty"`
+	Hash  string    `json:"hash"`
+}
+
+// stateHash fingerprints everything that decides how the game goes on.
+func (g *Game) stateHash() (string, error) {
+	s, err := g.snapshot()
+	if err != nil {
+		return "", err
+	}
+	// Absolute times differ between runs; the clock state does not
+	s.SavedAt, s.GameStart = time.Time{}, time.Time{}
+
+	state := struct {
+		Save    *saveFile `json:"save"`
+		Active  int       `json:"active"`
+		Menu    bool      `json:"menu"`
+		Outcome Outcome   `json:"outcome"`
+	}{Save: s, Menu: g.menuOpen, Outcome: g.outcome}
+	if g.activeQuest != nil {
+		state.Active = g.activeQuest.ID
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Recorder writes every step of a game to a transcript file, one JSON
+// object per line. A nil Recorder records nothing.
+type Recorder struct {
+	file *os.File
+	enc  *json.Encoder
+	err  error // first write error, reported by Close
+}
+
+// NewRecorder starts a transcript of g. level and quests are the files the
+// game was loaded from, so a replay can load them again.
+func NewRecorder(path string, g *Game, level, quests string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Recorder{file: file, enc: json.NewEncoder(file)}
+	r.enc.SetEscapeHTML(false)
+	r.err = r.enc.Encode(transcriptHeader{
+		Version:    transcriptVersion,
+		Seed:       g.Seed,
+		Level:      level,
+		Quests:     quests,
+		Mode:       g.Config.Mode,
+		PauseMenus: g.Config.Clock.PauseInMenus,
+		Start:      g.GameStart,
+	})
+	return r, nil
+}
+
+// Record appends the step g has just processed.
+func (r *Recorder) Record(g *Game, input string, tick bool) {
+	if r == nil || r.err != nil {
+		return
+	}
+	hash, err := g.stateHash()
+	if err != nil {
+		r.err = err
+		return
+	}
+	r.err = r.enc.Encode(transcriptEntry{Time: g.now(), Input: input, Tick: tick, Hash: hash})
+}
+
+// Close finishes the transcript and reports any error met while writing it.
+func (r *Recorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	if err := r.file.Close(); r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+// Replay re-runs a recorded game without any output or delays and checks
+// that every step leaves the game in the recorded state. It reports the
+// progress to out and returns how the game ended.
+func Replay(path string, out io.Writer) (Outcome, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return OutcomeContinue, err
+	}
+	defer file.Close()
+
+	lines := bufio.NewScanner(file)
+	lines.Buffer(make([]byte, 64*1024), 1024*1024)
+	if !lines.Scan() {
+		return OutcomeContinue, fmt.Errorf("%s: empty transcript", path)
+	}
+	var header transcriptHeader
+	if err := json.Unmarshal(lines.Bytes(), &header); err != nil {
+		return OutcomeContinue, fmt.Errorf("%s:1: %v", path, err)
+	}
+	if header.Version != transcriptVersion {
+		return OutcomeContinue, fmt.Errorf("%s: unsupported transcript version %d", path, header.Version)
+	}
+
+	level, err := LoadLevel(header.Level)
+	if err != nil {
+		return OutcomeContinue, err
+	}
+	quests, err := LoadQuests(header.Quests)
+	if err == nil {
+		err = ValidateRequirements(level, quests)
+	}
+	if err != nil {
+		return OutcomeContinue, err
+	}
+
+	// Saves made during the replay go to a directory of their own, so
+	// they neither overwrite nor read the player's real save slots
+	saves, err := os.MkdirTemp("", "go-quest-replay-")
+	if err != nil {
+		return OutcomeContinue, err
+	}
+	defer os.RemoveAll(saves)
+
+	// The game runs on the recorded timestamps instead of the wall clock
+	now := header.Start
+	clock := DefaultClockConfig()
+	clock.PauseInMenus = header.PauseMenus
+	clock.Now = func() time.Time { return now }
+	g := NewGame(GameConfig{Level: level, Quests: quests, Clock: clock, Seed: header.Seed, Mode: header.Mode, SaveDir: saves})
+
+	steps := 0
+	outcome := OutcomeContinue
+	for line := 2; lines.Scan(); line++ {
+		var entry transcriptEntry
+		if err := json.Unmarshal(lines.Bytes(), &entry); err != nil {
+			return outcome, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+
+		now = entry.Time
+		var result Result
+		if entry.Tick {
+			result = g.Tick()
+		} else {
+			result = g.ProcessCommand(entry.Input)
+		}
+		outcome = result.Outcome
+		steps++
+
+		hash, err := g.stateHash()
+		if err != nil {
+			return outcome, err
+		}
+		if hash != entry.Hash {
+			step := fmt.Sprintf("command %q", entry.Input)
+			if entry.Tick {
+				step = "clock tick"
+			}
+			return outcome, fmt.Errorf("%s:%d: %s at +%s left the game in a different state than recorded", path, line, step, entry.Time.Sub(header.Start).Round(time.Millisecond))
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return outcome, err
+	}
+
+	fmt.Fprintf(out, "Replayed %d steps from %s: every state matches, outcome %s.\n", steps, path, outcome)
+	return outcome, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordProtocol plays commands through the JSON protocol and records them
+// to a transcript in a temporary directory.
+func recordProtocol(t *testing.T, commands ...ProtocolCommand) string {
+	t.Helper()
+	cfg, _ := testConfig(t, "normal", 42)
+	cfg.Clock.Now = func() time.Time { return time.Now().Round(0) }
+	g := NewGame(cfg)
+
+	path := filepath.Join(t.TempDir(), "run.jsonl")
+	rec, err := NewRecorder(path, g, "", "")
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	var in bytes.Buffer
+	for _, c := range commands {
+		line, _ := json.Marshal(c)
+		in.Write(append(line, '\n'))
+	}
+	serveProtocol(g, &in, io.Discard, rec)
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return path
+}
+
+func TestProtocolRecordingReplays(t *testing.T) {
+	path := recordProtocol(t,
+		ProtocolCommand{Cmd: "go", Direction: "north"},
+		ProtocolCommand{Cmd: "take", Item: "ключ"},
+		ProtocolCommand{Cmd: "stats"},
+	)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The header, the initial look and one line per command
+	if lines := strings.Count(string(data), "\n"); lines != 5 {
+		t.Fatalf("transcript has %d lines, want 5:\n%s", lines, data)
+	}
+	if _, err := Replay(path, io.Discard); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+}
+
+func TestReplayDetectsTampering(t *testing.T) {
+	path := recordProtocol(t, ProtocolCommand{Cmd: "go", Direction: "north"})
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(data), `"input":"go north"`, `"input":"go south"`, 1)
+	if err := os.WriteFile(path, []byte(tampered), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Replay(path, io.Discard); err == nil {
+		t.Fatal("Replay accepted a transcript whose command was changed")
+	}
+}
+
+func TestReplayKeepsSavesAway(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := recordProtocol(t,
+		ProtocolCommand{Cmd: "save", Slot: "replay"},
+		ProtocolCommand{Cmd: "load", Slot: "replay"},
+	)
+
+	// Replay from an empty working directory: nothing may appear in it
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if _, err := Replay(path, io.Discard); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, defaultSaveDir)); !os.IsNotExist(err) {
+		t.Fatalf("replay wrote to %s/ of the working directory", defaultSaveDir)
+	}
+}
@@ -0,0 +1,527 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Quest packs may be written in the subset of YAML that quest files need:
+// block mappings and sequences, plain and quoted scalars, literal (|) and
+// folded (>) block scalars, flow sequences and mappings of scalars, and
+// comments. Anchors, tags, multi-line plain scalars and several documents
+// in one file are reported as errors rather than misread.
+
+// yamlLine is a line of a YAML file that holds more than a comment.
+type yamlLine struct {
+	num    int    // 1-based line number
+	indent int    // leading spaces
+	text   string // without the indentation and comment
+}
+
+type yamlParser struct {
+	raw   []string
+	pos   int       // index into raw of the next line
+	ahead *yamlLine // the rest of a "- key: value" line, read as a line of its own
+}
+
+// plainScalar is an unquoted YAML scalar. Its type depends on where it is
+// read into: "solution: 42" is a string but "difficulty: 2" a number.
+type plainScalar string
+
+// yamlFloat matches the plain scalars read as floating-point numbers.
+var yamlFloat = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+
+// value returns s as the null, boolean, number or string it reads as.
+func (s plainScalar) value() interface{} {
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if n, err := strconv.ParseInt(string(s), 10, 64); err == nil {
+		return n
+	}
+	if yamlFloat.MatchString(string(s)) {
+		if f, err := strconv.ParseFloat(string(s), 64); err == nil {
+			return f
+		}
+	}
+	return string(s)
+}
+
+// parseYAMLQuests reads a YAML file holding a sequence of quests.
+func parseYAMLQuests(data []byte) ([]rawQuest, error) {
+	p := &yamlParser{raw: strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")}
+	for i, raw := range p.raw {
+		if strings.HasPrefix(strings.TrimLeft(raw, " "), "\t") {
+			return nil, lineError(i+1, "YAML indentation must be spaces, not tabs")
+		}
+	}
+
+	// A document start marker may open the file
+	if line, ok := p.peek(); ok && line.text == "---" {
+		p.next()
+	}
+	line, ok := p.peek()
+	if !ok {
+		return nil, nil
+	}
+	if line.indent != 0 || !isYAMLItem(line.text) {
+		return nil, lineError(line.num, "expected a YAML sequence of quests")
+	}
+	var lines []int
+	items, err := p.parseSeq(0, &lines)
+	if err != nil {
+		return nil, err
+	}
+	if line, ok := p.peek(); ok {
+		return nil, lineError(line.num, "unexpected %q after the quests", line.text)
+	}
+
+	quests := make([]rawQuest, len(items))
+	for i, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, lineError(lines[i], "expected a quest mapping")
+		}
+		fitYAML(fields, reflect.TypeOf(questDef{}))
+		quests[i] = rawQuest{line: lines[i], fields: fields}
+	}
+	return quests, nil
+}
+
+// peek returns the next line holding more than a comment without reading
+// it. It reports false at the end of the file.
+func (p *yamlParser) peek() (yamlLine, bool) {
+	if p.ahead != nil {
+		return *p.ahead, true
+	}
+	for ; p.pos < len(p.raw); p.pos++ {
+		raw := p.raw[p.pos]
+		text := strings.TrimLeft(raw, " ")
+		if text = yamlStripComment(text); text != "" {
+			return yamlLine{num: p.pos + 1, indent: len(raw) - len(strings.TrimLeft(raw, " ")), text: text}, true
+		}
+	}
+	return yamlLine{}, false
+}
+
+// next reads the line peek returned.
+func (p *yamlParser) next() {
+	if p.ahead != nil {
+		p.ahead = nil
+		return
+	}
+	p.pos++
+}
+
+// isYAMLItem reports whether text starts a sequence item.
+func isYAMLItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// parseBlock reads the mapping or sequence starting at the next line.
+func (p *yamlParser) parseBlock(indent int) (interface{}, error) {
+	if line, _ := p.peek(); isYAMLItem(line.text) {
+		return p.parseSeq(indent, nil)
+	}
+	return p.parseMap(indent)
+}
+
+// parseNested reads the value of a key or sequence item at indent that is
+// written on the lines below it, or returns nil if there is none. A
+// sequence may be indented as far as the key it belongs to.
+func (p *yamlParser) parseNested(indent int, key bool) (interface{}, error) {
+	line, ok := p.peek()
+	switch {
+	case !ok:
+		return nil, nil
+	case line.indent > indent:
+		return p.parseBlock(line.indent)
+	case key && line.indent == indent && isYAMLItem(line.text):
+		return p.parseSeq(indent, nil)
+	}
+	return nil, nil
+}
+
+// parseSeq reads a block sequence at indent, adding the line of every item
+// to lines if it is given.
+func (p *yamlParser) parseSeq(indent int, lines *[]int) ([]interface{}, error) {
+	items := []interface{}{}
+	for {
+		line, ok := p.peek()
+		if !ok || line.indent < indent || (line.indent == indent && !isYAMLItem(line.text)) {
+			return items, nil
+		}
+		if line.indent > indent {
+			return nil, indentError(line)
+		}
+		p.next()
+		if lines != nil {
+			*lines = append(*lines, line.num)
+		}
+
+		rest := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+		var item interface{}
+		var err error
+		switch {
+		case rest == "":
+			item, err = p.parseNested(indent, false)
+		case isYAMLItem(rest) || yamlKeyEnd(rest) >= 0:
+			// "- key: value" starts a mapping at the column of its key
+			col := line.indent + len(line.text) - len(rest)
+			p.ahead = &yamlLine{num: line.num, indent: col, text: rest}
+			item, err = p.parseBlock(col)
+		default:
+			item, err = p.parseInline(line, rest)
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+}
+
+// parseMap reads a block mapping at indent.
+func (p *yamlParser) parseMap(indent int) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	for {
+		line, ok := p.peek()
+		if !ok || line.indent < indent {
+			return m, nil
+		}
+		if line.indent > indent {
+			return nil, indentError(line)
+		}
+		end := yamlKeyEnd(line.text)
+		if end < 0 || isYAMLItem(line.text) {
+			return nil, lineError(line.num, "expected \"key: value\", found %q", line.text)
+		}
+		key, err := yamlScalar(line.num, line.text[:end])
+		if err != nil {
+			return nil, err
+		}
+		name := fmt.Sprint(key)
+		if _, dup := m[name]; dup {
+			return nil, lineError(line.num, "duplicate key %q", name)
+		}
+		p.next()
+
+		var value interface{}
+		if rest := strings.TrimLeft(line.text[end+1:], " "); rest == "" {
+			value, err = p.parseNested(indent, true)
+		} else {
+			value, err = p.parseInline(line, rest)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m[name] = value
+	}
+}
+
+// indentError reports a line indented deeper than the block it is in.
+func indentError(line yamlLine) error {
+	return lineError(line.num, "unexpected indentation: text over several lines needs | or >")
+}
+
+// parseInline reads the value written after the key or dash of line.
+func (p *yamlParser) parseInline(line yamlLine, text string) (interface{}, error) {
+	switch text[0] {
+	case '|', '>':
+		return p.parseBlockScalar(line, text)
+	case '[', '{':
+		return yamlFlow(line.num, text)
+	case '&', '*', '!', '%', '@', '`':
+		return nil, lineError(line.num, "unsupported YAML syntax %q", text)
+	}
+	return yamlScalar(line.num, text)
+}
+
+// parseBlockScalar reads the literal or folded text below line, whose
+// header is "|" or ">" with an optional chomping indicator.
+func (p *yamlParser) parseBlockScalar(line yamlLine, header string) (string, error) {
+	chomp := header[1:]
+	if chomp != "" && chomp != "-" && chomp != "+" {
+		return "", lineError(line.num, "unsupported block scalar header %q", header)
+	}
+
+	var lines []string
+	indent := -1
+	for ; p.pos < len(p.raw); p.pos++ {
+		raw := p.raw[p.pos]
+		text := strings.TrimLeft(raw, " ")
+		if text == "" {
+			lines = append(lines, "")
+			continue
+		}
+		n := len(raw) - len(text)
+		if indent < 0 {
+			if n <= line.indent {
+				break
+			}
+			indent = n
+		}
+		if n < indent {
+			break
+		}
+		lines = append(lines, raw[indent:])
+	}
+
+	// Trailing blank lines only count when they are kept
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+	var text string
+	if header[0] == '|' {
+		text = strings.Join(lines, "\n")
+	} else {
+		text = yamlFold(lines)
+	}
+	switch {
+	case chomp == "+":
+		text += strings.Repeat("\n", trailing+1)
+	case chomp == "" && len(lines) > 0:
+		text += "\n"
+	}
+	return text, nil
+}
+
+// yamlFold joins the lines of a folded block scalar: single line breaks
+// become spaces and every blank line a line break.
+func yamlFold(lines []string) string {
+	var b strings.Builder
+	breaks := 0
+	for i, line := range lines {
+		if line == "" {
+			breaks++
+			continue
+		}
+		if i > 0 {
+			if breaks > 0 {
+				b.WriteString(strings.Repeat("\n", breaks))
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		breaks = 0
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// yamlFlow reads a flow sequence or mapping of scalars.
+func yamlFlow(num int, text string) (interface{}, error) {
+	closing := map[byte]byte{'[': ']', '{': '}'}[text[0]]
+	if text[len(text)-1] != closing {
+		return nil, lineError(num, "expected %q at the end of %q", closing, text)
+	}
+	parts, err := yamlSplitFlow(num, text[1:len(text)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	if text[0] == '[' {
+		items := []interface{}{}
+		for _, part := range parts {
+			item, err := yamlScalar(num, part)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+	m := map[string]interface{}{}
+	for _, part := range parts {
+		end := yamlKeyEnd(part)
+		if end < 0 {
+			return nil, lineError(num, "expected \"key: value\", found %q", part)
+		}
+		key, err := yamlScalar(num, part[:end])
+		if err != nil {
+			return nil, err
+		}
+		value, err := yamlScalar(num, strings.TrimLeft(part[end+1:], " "))
+		if err != nil {
+			return nil, err
+		}
+		m[fmt.Sprint(key)] = value
+	}
+	return m, nil
+}
+
+// yamlSplitFlow splits the inside of a flow collection at its commas. A
+// trailing comma is allowed.
+func yamlSplitFlow(num int, text string) ([]string, error) {
+	var parts []string
+	start := 0
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '[' || c == '{':
+			return nil, lineError(num, "nested flow collections are not supported")
+		case c == '"' || c == '\'':
+			// Quotes only start a string at the start of an entry or value
+			if before := strings.TrimSpace(text[start:i]); before != "" && !strings.HasSuffix(before, ":") {
+				continue
+			}
+			end := yamlQuoteEnd(text[i:])
+			if end < 0 {
+				return nil, lineError(num, "unterminated string in %q", text)
+			}
+			i += end
+		case c == ',':
+			part := strings.TrimSpace(text[start:i])
+			if part == "" {
+				return nil, lineError(num, "empty entry in %q", text)
+			}
+			parts = append(parts, part)
+			start = i + 1
+		}
+	}
+	if part := strings.TrimSpace(text[start:]); part != "" {
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// yamlScalar reads a quoted or plain scalar.
+func yamlScalar(num int, text string) (interface{}, error) {
+	if text == "" || (text[0] != '"' && text[0] != '\'') {
+		return plainScalar(text), nil
+	}
+	end := yamlQuoteEnd(text)
+	if end < 0 {
+		return nil, lineError(num, "unterminated string %s", text)
+	}
+	if end != len(text)-1 {
+		return nil, lineError(num, "unexpected %q after a string", text[end+1:])
+	}
+	if text[0] == '\'' {
+		return strings.ReplaceAll(text[1:end], "''", "'"), nil
+	}
+	s, err := strconv.Unquote(text)
+	if err != nil {
+		return nil, lineError(num, "invalid string %s", text)
+	}
+	return s, nil
+}
+
+// yamlQuoteEnd returns the index of the quote closing the string text
+// starts with, or -1 if it is not closed.
+func yamlQuoteEnd(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case text[i] == quote:
+			// Single-quoted strings write a quote as ''
+			if quote == '\'' && i+1 < len(text) && text[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// yamlKeyEnd returns the index of the colon ending the key text starts
+// with, or -1 if text is not a "key: value" pair.
+func yamlKeyEnd(text string) int {
+	isEnd := func(i int) bool { return text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') }
+	if text[0] == '"' || text[0] == '\'' {
+		if end := yamlQuoteEnd(text); end >= 0 && end+1 < len(text) && isEnd(end+1) {
+			return end + 1
+		}
+		return -1
+	}
+	if text[0] == '[' || text[0] == '{' {
+		return -1
+	}
+	for i := range text {
+		if isEnd(i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// yamlStripComment removes a comment and trailing spaces from text. A # only
+// starts a comment at the start of the text or after a space.
+func yamlStripComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				if quote == '\'' && i+1 < len(text) && text[i+1] == '\'' {
+					i++
+				} else {
+					quote = 0
+				}
+			}
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return strings.TrimRight(text[:i], " \t")
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" [{,", text[i-1]) >= 0):
+			quote = c
+		}
+	}
+	return strings.TrimRight(text, " \t")
+}
+
+// fitYAML resolves the plain scalars in v as the type t it is read into,
+// given by the JSON names of its fields: strings keep the text as written,
+// everything else gets its YAML type. It returns the resolved value.
+func fitYAML(v interface{}, t reflect.Type) interface{} {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch v := v.(type) {
+	case plainScalar:
+		if value := v.value(); value == nil || t == nil || t.Kind() != reflect.String {
+			return value
+		}
+		return string(v)
+	case []interface{}:
+		var elem reflect.Type
+		if t != nil && t.Kind() == reflect.Slice {
+			elem = t.Elem()
+		}
+		for i := range v {
+			v[i] = fitYAML(v[i], elem)
+		}
+	case map[string]interface{}:
+		for key, field := range v {
+			v[key] = fitYAML(field, jsonFieldType(t, key))
+		}
+	}
+	return v
+}
+
+// jsonFieldType returns the type of the field of struct t that key is read
+// into, or nil if there is none.
+func jsonFieldType(t reflect.Type, key string) reflect.Type {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name == key {
+			return field.Type
+		}
+	}
+	return nil
+}
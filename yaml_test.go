@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAMLQuests(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want map[string]interface{}
+	}{
+		{"plain scalars", "- id: 7\n  solution: 42\n  hints: [1, true, ~]\n", map[string]interface{}{"id": int64(7), "solution": "42", "hints": []interface{}{"1", "true", nil}}},
+		{"quotes and comments", "- name: 'it''s # not a comment' # but this is\n  example: \"a\\tb\"\n", map[string]interface{}{"name": "it's # not a comment", "example": "a\tb"}},
+		{"literal block", "- example: |\n    one\n\n    two\n\n  id: 1\n", map[string]interface{}{"example": "one\n\ntwo\n", "id": int64(1)}},
+		{"stripped folded block", "- description: >-\n    one\n    two\n", map[string]interface{}{"description": "one two"}},
+		{"nested mapping", "-\n  coop:\n    players: 2\n    window: 1m\n", map[string]interface{}{"coop": map[string]interface{}{"players": int64(2), "window": "1m"}}},
+		{"sequence at the key's indent", "- hints:\n  - a\n  - b: c\n  id: 3\n", map[string]interface{}{"hints": []interface{}{"a", map[string]interface{}{"b": "c"}}, "id": int64(3)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quests, err := parseYAMLQuests([]byte(tt.yaml))
+			if err != nil {
+				t.Fatalf("parseYAMLQuests: %v", err)
+			}
+			if len(quests) != 1 || quests[0].line != 1 || !reflect.DeepEqual(quests[0].fields, tt.want) {
+				t.Errorf("got %+v, want %v at line 1", quests, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLQuestsErrors(t *testing.T) {
+	tests := []struct {
+		yaml string
+		want string
+	}{
+		{"id: 1\n", "1: expected a YAML sequence of quests"},
+		{"- id: 1\n\tname: x\n", "2: YAML indentation must be spaces"},
+		{"- id: 1\n  id: 2\n", `2: duplicate key "id"`},
+		{"- name: &anchor x\n", "1: unsupported YAML syntax"},
+		{"- hints: [a, [b]]\n", "1: nested flow collections are not supported"},
+		{"- name: \"open\n", "1: unterminated string"},
+		{"- id: 1\n---\n- id: 2\n", `2: unexpected "---" after the quests`},
+		{"- just text\n", "1: expected a quest mapping"},
+	}
+	for _, tt := range tests {
+		_, err := parseYAMLQuests([]byte(tt.yaml))
+		var loadErr LoadError
+		if !errors.As(err, &loadErr) || !strings.HasPrefix(fmt.Sprintf("%d: %s", loadErr.Line, loadErr.Msg), tt.want) {
+			t.Errorf("%q: error %v, want %q", tt.yaml, err, tt.want)
+		}
+	}
+}